@@ -0,0 +1,24 @@
+variable "host" {
+  type = string
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+data "k3s_etcd_snapshots" "main" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+}
+
+output "latest_snapshot" {
+  value = try(reverse(sort(data.k3s_etcd_snapshots.main.snapshots[*].created_at))[0], null)
+}
@@ -31,7 +31,12 @@ data "k3s_kubeconfig" "kubeconfig" {
     user        = var.user
     private_key = var.private_key
   }
-  hostname = "mylb-dns-name"
+  hostname        = "mylb-dns-name"
+  port            = 443
+  tls_server_name = "kubernetes"
+  context_name    = "prod"
+  cluster_name    = "prod"
+  user_name       = "prod-admin"
 
   depends_on = [k3s_server.main]
 }
@@ -0,0 +1,37 @@
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+variable "clusters" {
+  type = map(string)
+}
+
+resource "k3s_server" "main" {
+  for_each = var.clusters
+
+  auth = {
+    host        = each.value
+    user        = var.user
+    private_key = var.private_key
+  }
+}
+
+data "k3s_merged_kubeconfig" "ci" {
+  kubeconfigs = [
+    for name, server in k3s_server.main : {
+      name       = name
+      kubeconfig = server.kubeconfig
+    }
+  ]
+  current_context = "prod"
+}
+
+output "kubeconfig" {
+  value     = data.k3s_merged_kubeconfig.ci.kubeconfig
+  sensitive = true
+}
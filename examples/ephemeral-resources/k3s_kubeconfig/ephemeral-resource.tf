@@ -0,0 +1,38 @@
+variable "host" {
+  type = string
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+resource "k3s_server" "main" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+}
+
+ephemeral "k3s_kubeconfig" "admin" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+  hostname = "mylb-dns-name"
+
+  depends_on = [k3s_server.main]
+}
+
+provider "kubernetes" {
+  host                   = ephemeral.k3s_kubeconfig.admin.cluster_auth.server
+  cluster_ca_certificate = ephemeral.k3s_kubeconfig.admin.cluster_auth.certificate_authority_data
+  client_certificate     = ephemeral.k3s_kubeconfig.admin.cluster_auth.client_certificate_data
+  client_key             = ephemeral.k3s_kubeconfig.admin.cluster_auth.client_key_data
+}
@@ -0,0 +1,44 @@
+terraform {
+  required_providers {
+    k3s = {
+      source = "striveworks/k3s"
+    }
+  }
+}
+
+variable "host" {
+  type = string
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+locals {
+  registries = <<-EOT
+    mirrors:
+      docker.io:
+        endpoint: ["https://mirror.example.com"]
+    configs:
+      mirror.example.com:
+        tls:
+          insecure_skip_verify: false
+  EOT
+}
+
+resource "k3s_server" "main" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+  config   = provider::k3s::config_merge("node-label: [tier=control-plane]", "write-kubeconfig-mode: \"0600\"")
+  registry = provider::k3s::config_merge(local.registries, yamlencode({
+    configs = { "mirror.example.com" = { auth = { username = "k3s" } } }
+  }))
+}
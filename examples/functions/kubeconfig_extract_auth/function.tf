@@ -0,0 +1,23 @@
+terraform {
+  required_providers {
+    k3s = {
+      source = "striveworks/k3s"
+    }
+  }
+}
+
+variable "kubeconfig" {
+  type      = string
+  sensitive = true
+}
+
+locals {
+  auth = provider::k3s::kubeconfig_extract_auth(var.kubeconfig)
+}
+
+provider "kubernetes" {
+  host                   = local.auth.server
+  cluster_ca_certificate = local.auth.certificate_authority_data
+  client_certificate     = local.auth.client_certificate_data
+  client_key             = local.auth.client_key_data
+}
@@ -0,0 +1,17 @@
+terraform {
+  required_providers {
+    k3s = {
+      source = "striveworks/k3s"
+    }
+  }
+}
+
+variable "kubeconfig" {
+  type      = string
+  sensitive = true
+}
+
+output "kubeconfig" {
+  value     = provider::k3s::kubeconfig_set_server(var.kubeconfig, "https://k3s.example.com:6443")
+  sensitive = true
+}
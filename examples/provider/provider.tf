@@ -1,3 +1,18 @@
 provider "k3s" {
   k3s_version = "v1.33.1+k3s1" // optional
+
+  // optional, used by every node `auth` that leaves these unset
+  default_auth = {
+    user        = "ubuntu"
+    private_key = file("~/.ssh/id_ed25519")
+
+    bastion = {
+      host = "bastion.example.com"
+    }
+
+    timeouts = {
+      connect = "30s"
+      ready   = "5m"
+    }
+  }
 }
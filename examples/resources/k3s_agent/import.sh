@@ -0,0 +1,6 @@
+# Credentials are read from K3S_SSH_USER and either K3S_SSH_PRIVATE_KEY or K3S_SSH_PASSWORD
+export K3S_SSH_USER=ubuntu
+export K3S_SSH_PRIVATE_KEY="$(cat ~/.ssh/id_ed25519)"
+
+# agent,host[:port]
+terraform import 'k3s_agent.main[0]' agent,192.168.1.3
@@ -37,8 +37,16 @@ resource "k3s_agent" "main" {
     user        = var.user
     private_key = var.private_key
   }
-  kubeconfig = k3s_server.main.kubeconfig
-  server     = k3s_server.main.server
-  token      = k3s_server.main.token
-  config     = var.config
+  kubeconfig  = k3s_server.main.kubeconfig
+  server      = k3s_server.main.server
+  agent_token = k3s_server.main.agent_token
+  config      = var.config
+
+  wait_for_ready = {
+    timeout = "10m"
+  }
+
+  timeouts {
+    create = "30m"
+  }
 }
@@ -0,0 +1,49 @@
+variable "host" {
+  type = string
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+variable "rotated_at" {
+  description = "Change to rotate the certificates again"
+  type        = string
+  default     = "2025-05-25"
+}
+
+resource "k3s_server" "main" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+}
+
+resource "k3s_certificate_rotation" "main" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+  services = ["admin", "api-server"]
+  triggers = {
+    rotated_at = var.rotated_at
+  }
+
+  depends_on = [k3s_server.main]
+}
+
+output "kubeconfig" {
+  value     = k3s_certificate_rotation.main.kubeconfig
+  sensitive = true
+}
+
+output "certificate_expiry" {
+  value = k3s_certificate_rotation.main.certificate_expiry
+}
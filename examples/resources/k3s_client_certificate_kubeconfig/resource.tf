@@ -0,0 +1,33 @@
+variable "host" {
+  type = string
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+resource "k3s_server" "main" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+}
+
+resource "k3s_client_certificate_kubeconfig" "jane" {
+  server_kubeconfig = k3s_server.main.kubeconfig
+  user              = "jane"
+  groups            = ["developers"]
+  ttl               = "720h"
+  renew_before      = "168h"
+}
+
+output "kubeconfig" {
+  value     = k3s_client_certificate_kubeconfig.jane.kubeconfig
+  sensitive = true
+}
@@ -0,0 +1,48 @@
+variable "server_hosts" {
+  type = list(string)
+}
+
+variable "agent_hosts" {
+  type = list(string)
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+resource "k3s_cluster" "main" {
+  servers = [for i, host in var.server_hosts : {
+    name = "server-${i}"
+    auth = {
+      host        = host
+      user        = var.user
+      private_key = var.private_key
+    }
+    config = yamlencode({
+      "node-label" = ["role=control-plane"]
+    })
+  }]
+
+  agents = [for i, host in var.agent_hosts : {
+    name = "agent-${i}"
+    auth = {
+      host        = host
+      user        = var.user
+      private_key = var.private_key
+    }
+  }]
+
+  wait_for_ready = {
+    timeout = "10m"
+  }
+}
+
+output "kubeconfig" {
+  value     = k3s_cluster.main.kubeconfig
+  sensitive = true
+}
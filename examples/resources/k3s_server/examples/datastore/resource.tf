@@ -0,0 +1,53 @@
+variable "hosts" {
+  type = list(string)
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+variable "db_password" {
+  type      = string
+  sensitive = true
+}
+
+# PEM contents of the database CA, e.g. file("db-ca.crt")
+variable "db_ca" {
+  type = string
+}
+
+resource "k3s_server" "first" {
+  auth = {
+    host        = var.hosts[0]
+    user        = var.user
+    private_key = var.private_key
+  }
+  datastore = {
+    endpoint = "postgres://db.example.com:5432/k3s"
+    username = "k3s"
+    password = var.db_password
+    ca       = var.db_ca
+  }
+}
+
+resource "k3s_server" "others" {
+  count = length(var.hosts) - 1
+
+  auth = {
+    host        = var.hosts[count.index + 1]
+    user        = var.user
+    private_key = var.private_key
+  }
+  datastore = {
+    endpoint = "postgres://db.example.com:5432/k3s"
+    username = "k3s"
+    password = var.db_password
+    ca       = var.db_ca
+    token    = k3s_server.first.token
+  }
+}
@@ -0,0 +1,35 @@
+variable "host" {
+  type = string
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+resource "k3s_server" "main" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+
+  server_config = {
+    node_labels     = ["role=control-plane"]
+    tls_san         = ["k3s.example.com"]
+    disable         = ["traefik", "servicelb"]
+    cluster_cidr    = "10.42.0.0/16"
+    service_cidr    = "10.43.0.0/16"
+    flannel_backend = "wireguard-native"
+    kubelet_args    = ["max-pods=200"]
+  }
+
+  # Anything not covered by server_config
+  config = yamlencode({
+    "secrets-encryption" = true
+  })
+}
@@ -0,0 +1,30 @@
+variable "host" {
+  type = string
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+  ephemeral = true
+}
+
+provider "k3s" {
+  default_auth = {
+    user        = var.user
+    private_key = var.private_key
+  }
+}
+
+resource "k3s_server" "main" {
+  auth = {
+    host = var.host
+  }
+  auth_wo = {
+    private_key = var.private_key
+  }
+  auth_wo_version = 1
+}
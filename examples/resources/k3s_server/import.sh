@@ -0,0 +1,8 @@
+# Credentials are read from K3S_SSH_USER and either K3S_SSH_PRIVATE_KEY or K3S_SSH_PASSWORD
+export K3S_SSH_USER=ubuntu
+export K3S_SSH_PRIVATE_KEY="$(cat ~/.ssh/id_ed25519)"
+
+# server,host[:port]
+# The token, agent token and datastore endpoint of the remote config are imported
+# into highly_available and datastore rather than config
+terraform import k3s_server.main server,192.168.1.2:22
@@ -0,0 +1,33 @@
+variable "host" {
+  type = string
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+resource "k3s_server" "main" {
+  auth = {
+    host        = var.host
+    user        = var.user
+    private_key = var.private_key
+  }
+}
+
+resource "k3s_service_account_kubeconfig" "ci" {
+  server_kubeconfig = k3s_server.main.kubeconfig
+  name              = "ci"
+  namespace         = "apps"
+  cluster_role      = "edit"
+  ttl               = "24h"
+}
+
+output "kubeconfig" {
+  value     = k3s_service_account_kubeconfig.ci.kubeconfig
+  sensitive = true
+}
@@ -0,0 +1,40 @@
+variable "server_host" {
+  type = string
+}
+
+variable "other_server_hosts" {
+  type    = list(string)
+  default = []
+}
+
+variable "user" {
+  type = string
+}
+
+variable "private_key" {
+  type      = string
+  sensitive = true
+}
+
+# Short lived token to join a node with
+resource "k3s_token" "bootstrap" {
+  auth = {
+    host        = var.server_host
+    user        = var.user
+    private_key = var.private_key
+  }
+  ttl         = "2h"
+  description = "Node bootstrap"
+}
+
+# Rotate the server token, restarting every server with the new token
+resource "k3s_token" "rotation" {
+  auth = {
+    host        = var.server_host
+    user        = var.user
+    private_key = var.private_key
+  }
+  rotate = {
+    servers = var.other_server_hosts
+  }
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	api "k8s.io/client-go/tools/clientcmd/api"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+// Shortest lifetime the kubernetes signers accept.
+const minClientCertificateTtl = 10 * time.Minute
+
+// Lifetime and renewal window of a certificate when left unset.
+const (
+	DefaultClientCertificateTtl         = "720h"
+	DefaultClientCertificateRenewBefore = "168h"
+)
+
+type ClientCertificateKubeConfigModel struct {
+	ServerKubeConfig types.String `tfsdk:"server_kubeconfig"`
+	User             types.String `tfsdk:"user"`
+	Groups           types.List   `tfsdk:"groups"`
+	Ttl              types.String `tfsdk:"ttl"`
+	RenewBefore      types.String `tfsdk:"renew_before"`
+	// Outputs
+	Id                types.String `tfsdk:"id"`
+	ClientCertificate types.String `tfsdk:"client_certificate"`
+	ClientKey         types.String `tfsdk:"client_key"`
+	ExpiresAt         types.String `tfsdk:"expires_at"`
+	KubeConfig        types.String `tfsdk:"kubeconfig"`
+}
+
+func (c ClientCertificateKubeConfigModel) Validate() error {
+	// Unset values are compared as their defaults, unknown ones only once known
+	ttl, err := time.ParseDuration(DefaultClientCertificateTtl)
+	if err != nil {
+		return err
+	}
+	renewBefore, err := time.ParseDuration(DefaultClientCertificateRenewBefore)
+	if err != nil {
+		return err
+	}
+
+	if !c.Ttl.IsNull() && !c.Ttl.IsUnknown() {
+		if ttl, err = time.ParseDuration(c.Ttl.ValueString()); err != nil {
+			return fmt.Errorf("ttl %s is not a duration: %s", c.Ttl.ValueString(), err.Error())
+		}
+		if ttl < minClientCertificateTtl {
+			return fmt.Errorf("ttl must be at least %s, got %s", minClientCertificateTtl, ttl)
+		}
+	}
+	if !c.RenewBefore.IsNull() && !c.RenewBefore.IsUnknown() {
+		if renewBefore, err = time.ParseDuration(c.RenewBefore.ValueString()); err != nil {
+			return fmt.Errorf("renew_before %s is not a duration: %s", c.RenewBefore.ValueString(), err.Error())
+		}
+		if renewBefore < 0 {
+			return fmt.Errorf("renew_before can't be negative, got %s", renewBefore)
+		}
+	}
+	if !c.Ttl.IsUnknown() && !c.RenewBefore.IsUnknown() && renewBefore >= ttl {
+		return fmt.Errorf("renew_before %s must be shorter than the ttl %s", renewBefore, ttl)
+	}
+	return nil
+}
+
+func (c ClientCertificateKubeConfigModel) certificate(ctx context.Context) (k3s.ClientCertificate, error) {
+	groups := []string{}
+	if !c.Groups.IsNull() && !c.Groups.IsUnknown() {
+		if diags := c.Groups.ElementsAs(ctx, &groups, false); diags.HasError() {
+			return k3s.ClientCertificate{}, fmt.Errorf("reading groups")
+		}
+	}
+	return k3s.ClientCertificate{
+		User:   c.User.ValueString(),
+		Groups: groups,
+		Ttl:    parseDurationOr(c.Ttl.ValueString(), parseDurationOr(DefaultClientCertificateTtl, 0)),
+	}, nil
+}
+
+// Whether the certificate is within renew_before of its expiry and needs to be issued again.
+func (c ClientCertificateKubeConfigModel) NeedsRenewal(now time.Time) bool {
+	expiresAt, err := time.Parse(time.RFC3339, c.ExpiresAt.ValueString())
+	if err != nil {
+		return false
+	}
+	renewBefore := parseDurationOr(c.RenewBefore.ValueString(), 0)
+	return !now.Before(expiresAt.Add(-renewBefore))
+}
+
+func (c *ClientCertificateKubeConfigModel) Create(ctx context.Context, certificates k3s.ClientCertificates) error {
+	if err := c.issue(ctx, certificates); err != nil {
+		return err
+	}
+	c.Id = types.StringValue(c.User.ValueString())
+	return nil
+}
+
+// Issues a new certificate when the plan marked it for renewal.
+func (c *ClientCertificateKubeConfigModel) Update(ctx context.Context, inc ClientCertificateKubeConfigModel, certificates k3s.ClientCertificates) error {
+	renew := inc.ClientCertificate.IsUnknown()
+	c.Ttl = inc.Ttl
+	c.RenewBefore = inc.RenewBefore
+	c.ServerKubeConfig = inc.ServerKubeConfig
+	if !renew {
+		tflog.Debug(ctx, "No change is needed, the certificate is still valid")
+		return nil
+	}
+
+	return c.issue(ctx, certificates)
+}
+
+func (c *ClientCertificateKubeConfigModel) issue(ctx context.Context, certificates k3s.ClientCertificates) error {
+	cert, err := c.certificate(ctx)
+	if err != nil {
+		return err
+	}
+	certPEM, keyPEM, err := certificates.IssueClientCertificate(cert)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "client certificate issued")
+
+	expiresAt, err := certificateExpiry(certPEM)
+	if err != nil {
+		return err
+	}
+
+	kubeconfig, err := userKubeConfig(c.ServerKubeConfig.ValueString(), cert.User, "", &api.AuthInfo{
+		ClientCertificateData: []byte(certPEM),
+		ClientKeyData:         []byte(keyPEM),
+	})
+	if err != nil {
+		return fmt.Errorf("rendering kubeconfig: %s", err.Error())
+	}
+
+	c.ClientCertificate = types.StringValue(certPEM)
+	c.ClientKey = types.StringValue(keyPEM)
+	c.ExpiresAt = types.StringValue(expiresAt.UTC().Format(time.RFC3339))
+	c.KubeConfig = types.StringValue(kubeconfig)
+	return nil
+}
+
+// The signer may cap the requested lifetime, so the expiry is read from the certificate itself.
+func certificateExpiry(certPEM string) (time.Time, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return time.Time{}, fmt.Errorf("issued certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing issued certificate: %s", err.Error())
+	}
+	return cert.NotAfter, nil
+}
+
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return duration
+}
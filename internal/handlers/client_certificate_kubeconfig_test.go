@@ -0,0 +1,162 @@
+package handlers_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+type mockClientCertificates struct {
+	notAfter time.Time
+
+	issued []k3s.ClientCertificate
+}
+
+func (m *mockClientCertificates) IssueClientCertificate(cert k3s.ClientCertificate) (string, string, error) {
+	m.issued = append(m.issued, cert)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(len(m.issued))),
+		Subject:      pkix.Name{CommonName: cert.User, Organization: cert.Groups},
+		NotBefore:    time.Now(),
+		NotAfter:     m.notAfter,
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})), nil
+}
+
+func clientCertificateModel() handlers.ClientCertificateKubeConfigModel {
+	return handlers.ClientCertificateKubeConfigModel{
+		ServerKubeConfig: types.StringValue(TestMockKubeconfig),
+		User:             types.StringValue("jane"),
+		Groups:           types.ListValueMust(types.StringType, []attr.Value{types.StringValue("developers")}),
+		Ttl:              types.StringValue("720h"),
+		RenewBefore:      types.StringValue("168h"),
+	}
+}
+
+func TestClientCertificateKubeConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*handlers.ClientCertificateKubeConfigModel)
+		valid  bool
+	}{
+		{"defaults", func(*handlers.ClientCertificateKubeConfigModel) {}, true},
+		{"short ttl", func(m *handlers.ClientCertificateKubeConfigModel) { m.Ttl = types.StringValue("1m") }, false},
+		{"bad ttl", func(m *handlers.ClientCertificateKubeConfigModel) { m.Ttl = types.StringValue("soon") }, false},
+		{"bad renew_before", func(m *handlers.ClientCertificateKubeConfigModel) { m.RenewBefore = types.StringValue("soon") }, false},
+		{"renew_before past ttl", func(m *handlers.ClientCertificateKubeConfigModel) { m.RenewBefore = types.StringValue("720h") }, false},
+		{"short ttl with default renew_before", func(m *handlers.ClientCertificateKubeConfigModel) {
+			m.Ttl = types.StringValue("24h")
+			m.RenewBefore = types.StringNull()
+		}, false},
+		{"default ttl", func(m *handlers.ClientCertificateKubeConfigModel) {
+			m.Ttl = types.StringNull()
+			m.RenewBefore = types.StringValue("700h")
+		}, true},
+		{"renew_before past default ttl", func(m *handlers.ClientCertificateKubeConfigModel) {
+			m.Ttl = types.StringNull()
+			m.RenewBefore = types.StringValue("800h")
+		}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := clientCertificateModel()
+			tc.modify(&m)
+			if err := m.Validate(); (err == nil) != tc.valid {
+				t.Errorf("Expected valid %v, got %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestClientCertificateKubeConfigCreate(t *testing.T) {
+	t.Parallel()
+
+	// Signers cap the lifetime, the expiry must come from the certificate
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	certificates := &mockClientCertificates{notAfter: notAfter}
+	m := clientCertificateModel()
+
+	if err := m.Create(t.Context(), certificates); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	if len(certificates.issued) != 1 || certificates.issued[0].User != "jane" || certificates.issued[0].Ttl != 720*time.Hour {
+		t.Errorf("Unexpected certificate request %v", certificates.issued)
+	}
+	if m.Id.ValueString() != "jane" || m.ExpiresAt.ValueString() != "2030-01-01T00:00:00Z" {
+		t.Errorf("Unexpected outputs %s %s", m.Id, m.ExpiresAt)
+	}
+
+	config, err := clientcmd.Load([]byte(m.KubeConfig.ValueString()))
+	if err != nil {
+		t.Fatalf("Expected a valid kubeconfig, got %v", err.Error())
+	}
+	current := config.Contexts[config.CurrentContext]
+	if current == nil || current.AuthInfo != "jane" {
+		t.Fatalf("Expected a context for jane, got %v", current)
+	}
+	user := config.AuthInfos[current.AuthInfo]
+	if string(user.ClientCertificateData) != m.ClientCertificate.ValueString() || string(user.ClientKeyData) != m.ClientKey.ValueString() {
+		t.Errorf("Expected the issued certificate in the kubeconfig")
+	}
+	if config.Clusters[current.Cluster].Server != "https://127.0.0.1:6443" {
+		t.Errorf("Expected the cluster of the server kubeconfig, got %v", config.Clusters[current.Cluster])
+	}
+}
+
+func TestClientCertificateKubeConfigUpdate(t *testing.T) {
+	t.Parallel()
+
+	certificates := &mockClientCertificates{notAfter: time.Now().Add(720 * time.Hour)}
+	state := clientCertificateModel()
+	if err := state.Create(t.Context(), certificates); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	issued := state.ClientCertificate
+
+	plan := state
+	if err := state.Update(t.Context(), plan, certificates); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	if len(certificates.issued) != 1 {
+		t.Errorf("Expected the certificate to be kept, got %d issued", len(certificates.issued))
+	}
+
+	plan.RenewBefore = types.StringValue("24h")
+	plan.ClientCertificate = types.StringUnknown()
+	if err := state.Update(t.Context(), plan, certificates); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	if len(certificates.issued) != 2 || state.ClientCertificate.Equal(issued) || state.RenewBefore.ValueString() != "24h" {
+		t.Errorf("Expected a reissued certificate, got %d issued", len(certificates.issued))
+	}
+}
+
+func TestClientCertificateKubeConfigNeedsRenewal(t *testing.T) {
+	t.Parallel()
+
+	m := clientCertificateModel()
+	m.ExpiresAt = types.StringValue("2030-01-08T00:00:00Z")
+	if m.NeedsRenewal(time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected certificate to still be valid")
+	}
+	if !m.NeedsRenewal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected certificate to be renewed within renew_before of its expiry")
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+type K3sEtcdSnapshots struct {
+	Auth      types.Object `tfsdk:"auth"`
+	Snapshots types.List   `tfsdk:"snapshots"`
+}
+
+type EtcdSnapshot struct {
+	Name      types.String `tfsdk:"name"`
+	NodeName  types.String `tfsdk:"node_name"`
+	Location  types.String `tfsdk:"location"`
+	Size      types.Int64  `tfsdk:"size"`
+	CreatedAt types.String `tfsdk:"created_at"`
+}
+
+func (EtcdSnapshot) Schema() schema.Attribute {
+	return schema.ListNestedAttribute{
+		Computed:    true,
+		Description: "Etcd snapshots available to the server",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					Computed:            true,
+					MarkdownDescription: "Name of the snapshot",
+				},
+				"node_name": schema.StringAttribute{
+					Computed:            true,
+					MarkdownDescription: "Node which took the snapshot",
+				},
+				"location": schema.StringAttribute{
+					Computed:            true,
+					MarkdownDescription: "Location of the snapshot, either a `file://` or `s3://` url",
+				},
+				"size": schema.Int64Attribute{
+					Computed:            true,
+					MarkdownDescription: "Size of the snapshot in bytes",
+				},
+				"created_at": schema.StringAttribute{
+					Computed:            true,
+					MarkdownDescription: "RFC3339 timestamp of when the snapshot was taken",
+				},
+			},
+		},
+	}
+}
+
+func (EtcdSnapshot) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"name":       types.StringType,
+		"node_name":  types.StringType,
+		"location":   types.StringType,
+		"size":       types.Int64Type,
+		"created_at": types.StringType,
+	}
+}
+
+func (m *EtcdSnapshot) ToObject(ctx context.Context) basetypes.ObjectValue {
+	return ToObject(ctx, m)
+}
+
+func NewEtcdSnapshot(snapshot k3s.EtcdSnapshot) EtcdSnapshot {
+	return EtcdSnapshot{
+		Name:      types.StringValue(snapshot.Name),
+		NodeName:  types.StringValue(snapshot.NodeName),
+		Location:  types.StringValue(snapshot.Location),
+		Size:      types.Int64Value(snapshot.Size),
+		CreatedAt: types.StringValue(snapshot.CreatedAt),
+	}
+}
+
+// Performs the read operation on the k3s_etcd_snapshots data source.
+func (s *K3sEtcdSnapshots) Read(ctx context.Context, auth K3sTypeSSH, server k3s.ServerEtcdSnapshots) error {
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+	tflog.Debug(ctx, "k3s etcd snapshots ssh client created")
+
+	snapshots, err := server.EtcdSnapshots(sshClient)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, fmt.Sprintf("found %d etcd snapshots", len(snapshots)))
+
+	objects := make([]attr.Value, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		obj := NewEtcdSnapshot(snapshot)
+		objects = append(objects, obj.ToObject(ctx))
+	}
+
+	list, diags := types.ListValue(types.ObjectType{AttrTypes: EtcdSnapshot{}.AttributeTypes()}, objects)
+	if diags.HasError() {
+		return fmt.Errorf("building etcd snapshot list")
+	}
+	s.Snapshots = list
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"fmt"
+	"testing"
+
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+type mockEtcdSnapshots struct {
+	snapshots []k3s.EtcdSnapshot
+	err       error
+}
+
+func (m mockEtcdSnapshots) EtcdSnapshots(ssh_client.SSHRun) ([]k3s.EtcdSnapshot, error) {
+	return m.snapshots, m.err
+}
+
+func TestEtcdSnapshotsHandlerRead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Bad ssh", func(t *testing.T) {
+		var data handlers.K3sEtcdSnapshots
+		err := data.Read(t.Context(), &mockKubeConfigBadSSH{}, &mockEtcdSnapshots{})
+		if err == nil {
+			t.Errorf("Bad ssh should raise, got nil")
+		}
+	})
+
+	t.Run("Bad list", func(t *testing.T) {
+		var data handlers.K3sEtcdSnapshots
+		err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockEtcdSnapshots{err: fmt.Errorf("error")})
+		if err == nil {
+			t.Errorf("Bad list should raise, got nil")
+		}
+	})
+
+	t.Run("Good list", func(t *testing.T) {
+		var data handlers.K3sEtcdSnapshots
+		err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockEtcdSnapshots{
+			snapshots: []k3s.EtcdSnapshot{
+				{Name: "a", Size: 10},
+				{Name: "b", Size: 20},
+			},
+		})
+		if err != nil {
+			t.Errorf("Good list shouldn't raise, got %s", err.Error())
+		}
+		if len(data.Snapshots.Elements()) != 2 {
+			t.Errorf("Expected 2 snapshots, got %d", len(data.Snapshots.Elements()))
+		}
+	})
+}
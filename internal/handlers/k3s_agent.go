@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"striveworks.us/terraform-provider-k3s/internal/k3s"
@@ -11,34 +13,71 @@ import (
 
 type AgentClientModel struct {
 	Auth types.Object `tfsdk:"auth"`
+	// Write-only credentials, never stored in state
+	AuthWo        types.Object `tfsdk:"auth_wo"`
+	AuthWoVersion types.Int64  `tfsdk:"auth_wo_version"`
 	// Connection
 	Server types.String `tfsdk:"server"`
 	BinDir types.String `tfsdk:"bin_dir"`
 	// Configs
 	KubeConfig     types.String `tfsdk:"kubeconfig"`
-	K3sRegistry    types.String `tfsdk:"registry"`
-	K3sConfig      types.String `tfsdk:"config"`
+	K3sRegistry    YamlValue    `tfsdk:"registry"`
+	K3sConfig      YamlValue    `tfsdk:"config"`
+	AgentConfig    types.Object `tfsdk:"agent_config"`
 	Token          types.String `tfsdk:"token"`
+	AgentToken     types.String `tfsdk:"agent_token"`
+	TokenWo        types.String `tfsdk:"token_wo"`
+	TokenWoVersion types.Int64  `tfsdk:"token_wo_version"`
 	AllowDeleteErr types.Bool   `tfsdk:"allow_delete_err"`
 	// Outputs
 	Id     types.String `tfsdk:"id"`
 	Active types.Bool   `tfsdk:"active"`
+	// Wait for the node to be Ready after install
+	WaitForReady types.Object `tfsdk:"wait_for_ready"`
+	// Deadlines of each operation
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
 
 	version string
 }
 
 func (a *AgentClientModel) ToAgent(ctx context.Context) (k3s.Agent, error) {
+	config := a.K3sConfig.ValueString()
+	if !a.AgentConfig.IsNull() && !a.AgentConfig.IsUnknown() {
+		merged, err := mergeConfig(a.K3sConfig, NewAgentConfig(ctx, a.AgentConfig).config(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("merging agent_config: %s", err.Error())
+		}
+		config = merged
+	}
+
 	return k3s.NewK3sAgentComponent(
 		ctx,
-		a.K3sConfig.ValueString(),
+		config,
 		a.K3sRegistry.ValueString(),
 		a.version,
-		a.Token.ValueString(),
+		a.joinToken(),
 		a.Server.ValueString(),
 		a.BinDir.ValueString(),
 	)
 }
 
+// Either the server token or the agent only token, whichever was passed.
+func (a *AgentClientModel) joinToken() string {
+	if !a.AgentToken.IsNull() {
+		return a.AgentToken.ValueString()
+	}
+	if !a.TokenWo.IsNull() {
+		return a.TokenWo.ValueString()
+	}
+	return a.Token.ValueString()
+}
+
+// Write-only values are never part of a plan, they are taken from the config.
+func (a *AgentClientModel) SetWriteOnly(config AgentClientModel) {
+	a.AuthWo = config.AuthWo
+	a.TokenWo = config.TokenWo
+}
+
 // Hides version so terraform doesn't expose it on the model.
 func (a *AgentClientModel) SetVersion(version *string) {
 	if version != nil {
@@ -55,6 +94,8 @@ type TK3sAgentRead interface {
 	k3s.ComponentResync
 	k3s.ComponentStatus
 	k3s.ComponentToken
+	k3s.AgentConfig
+	k3s.AgentRegistry
 	k3s.AgentServer
 }
 
@@ -69,11 +110,22 @@ func (a *AgentClientModel) Read(
 	}
 	tflog.Debug(ctx, "k3s agent ssh client created")
 
+	// What the provider manages, before being replaced with the remote files
+	config, registry := agent.Config(), agent.Registry()
+
 	if err := agent.Resync(sshClient); err != nil {
 		return fmt.Errorf("error resyncing agent: %s", err.Error())
 	}
 	tflog.Debug(ctx, "k3s agent resynced")
 
+	if a.K3sConfig, err = driftedYaml(a.K3sConfig, config, agent.Config()); err != nil {
+		return fmt.Errorf("comparing remote config: %s", err.Error())
+	}
+	if a.K3sRegistry, err = driftedYaml(a.K3sRegistry, registry, agent.Registry()); err != nil {
+		return fmt.Errorf("comparing remote registry: %s", err.Error())
+	}
+	tflog.Debug(ctx, "k3s agent config and registry compared")
+
 	status, err := agent.Status(sshClient)
 	if err != nil {
 		return fmt.Errorf("fetching status or status logs: %s", err.Error())
@@ -81,7 +133,48 @@ func (a *AgentClientModel) Read(
 
 	a.Active = types.BoolValue(status)
 	a.Server = types.StringValue(agent.Server())
-	a.Token = types.StringValue(agent.Token())
+	// Only the configured token is refreshed, a write-only token never reaches state
+	switch {
+	case !a.TokenWo.IsNull() || !a.TokenWoVersion.IsNull():
+	case a.AgentToken.IsNull():
+		a.Token = types.StringValue(agent.Token())
+	default:
+		a.AgentToken = types.StringValue(agent.Token())
+	}
+
+	return nil
+}
+
+// Adopts an existing k3s agent, populating the model from the remote node.
+func (a *AgentClientModel) Import(
+	ctx context.Context,
+	auth TAgentRead,
+	agent TK3sAgentRead,
+) error {
+	a.AuthWo = types.ObjectNull(AuthWriteOnly{}.AttributeTypes())
+	a.AgentConfig = types.ObjectNull(AgentConfig{}.AttributeTypes())
+	a.WaitForReady = types.ObjectNull(WaitForReady{}.AttributeTypes())
+	a.Timeouts = DefaultTimeouts()
+	if a.BinDir.IsNull() {
+		a.BinDir = types.StringValue(k3s.BIN_DIR)
+	}
+
+	// Nothing is managed yet, so Read takes the remote config and registry as they are
+	if err := a.Read(ctx, auth, agent); err != nil {
+		return err
+	}
+
+	// Agent tokens generated by k3s are scoped to nodes, anything else is taken as the server token
+	if strings.Contains(a.Token.ValueString(), "::node:") {
+		a.AgentToken = a.Token
+		a.Token = types.StringNull()
+	}
+
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+	a.Id = types.StringValue(fmt.Sprintf("agent,%s", sshClient.HostnameOrIpAddress()))
 
 	return nil
 }
@@ -109,6 +202,7 @@ type TK3sAgentCreate interface {
 	k3s.ComponentPreInstall
 	k3s.ComponentInstall
 	k3s.ComponentStatus
+	k3s.ComponentWaitForReady
 }
 
 func (a *AgentClientModel) Create(
@@ -136,6 +230,13 @@ func (a *AgentClientModel) Create(
 	if err != nil {
 		return fmt.Errorf("fetching status or status logs: %s", err.Error())
 	}
+
+	if !a.WaitForReady.IsNull() {
+		if err := agent.WaitForReady(sshClient, a.KubeConfig.ValueString(), NewWaitForReady(ctx, a.WaitForReady).timeout()); err != nil {
+			return fmt.Errorf("waiting for k3s agent to be ready: %s", err.Error())
+		}
+		tflog.Debug(ctx, "k3s agent ready")
+	}
 	a.Active = types.BoolValue(status)
 	a.Id = types.StringValue(fmt.Sprintf("agent,%s", sshClient.HostnameOrIpAddress()))
 
@@ -160,7 +261,9 @@ func (existing *AgentClientModel) Update(
 	}
 	tflog.Debug(ctx, "k3s agent ssh client created")
 
-	if existing.K3sConfig.Equal(inc.K3sConfig) && existing.K3sRegistry.Equal(inc.K3sRegistry) {
+	if existing.K3sConfig.semanticEqual(inc.K3sConfig) &&
+		existing.K3sRegistry.semanticEqual(inc.K3sRegistry) &&
+		existing.AgentConfig.Equal(inc.AgentConfig) {
 		tflog.Debug(ctx, "No change is needed, only supporting config and registry updates")
 		return nil
 	}
@@ -182,6 +285,7 @@ func (existing *AgentClientModel) Update(
 	existing.Active = types.BoolValue(status)
 	existing.K3sRegistry = inc.K3sRegistry
 	existing.K3sConfig = inc.K3sConfig
+	existing.AgentConfig = inc.AgentConfig
 
 	return nil
 }
@@ -3,6 +3,7 @@ package handlers_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"striveworks.us/terraform-provider-k3s/internal/handlers"
@@ -14,14 +15,28 @@ type mockAgent struct {
 	statusErr error
 	resync    error
 	uninstall error
+	// Managed config, replaced by the remote config on resync
+	config       map[any]any
+	remoteConfig map[any]any
+	resynced     bool
 }
 
-func (m mockAgent) Resync(ssh_client.SSHClient) error { return m.resync }
+func (m *mockAgent) Resync(ssh_client.SSHClient) error {
+	m.resynced = true
+	return m.resync
+}
 func (m mockAgent) Status(ssh_client.SSHClient) (bool, error) {
 	return m.status, m.statusErr
 }
 func (mockAgent) Server() string { return "" }
-func (mockAgent) Token() string  { return "" }
+func (mockAgent) Token() string  { return "token" }
+func (m mockAgent) Config() map[any]any {
+	if m.resynced {
+		return m.remoteConfig
+	}
+	return m.config
+}
+func (mockAgent) Registry() map[any]any { return nil }
 func (m mockAgent) Uninstall(ssh_client.SSHClient, string, ...bool) error {
 	return m.uninstall
 }
@@ -66,6 +81,35 @@ func TestAgentHandlerRead(t *testing.T) {
 		if !data.Active.Equal(types.BoolValue(true)) {
 			t.Errorf("Good status should result in true active")
 		}
+		if !data.Token.Equal(types.StringValue("token")) {
+			t.Errorf("Expected token to be resynced, got %s", data.Token)
+		}
+	})
+
+	t.Run("good read with agent token", func(t *testing.T) {
+		data := handlers.AgentClientModel{AgentToken: types.StringValue("old")}
+		err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockAgent{status: true})
+		if err != nil {
+			t.Errorf("Bad status shouldn't raise")
+		}
+		if !data.Token.IsNull() {
+			t.Errorf("Token should stay null when using an agent token, got %s", data.Token)
+		}
+		if !data.AgentToken.Equal(types.StringValue("token")) {
+			t.Errorf("Expected agent token to be resynced, got %s", data.AgentToken)
+		}
+	})
+
+	t.Run("good read with write-only token", func(t *testing.T) {
+		// token_wo itself is never in state, only its version
+		data := handlers.AgentClientModel{TokenWoVersion: types.Int64Value(1)}
+		err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockAgent{status: true})
+		if err != nil {
+			t.Errorf("Bad status shouldn't raise")
+		}
+		if !data.Token.IsNull() || !data.AgentToken.IsNull() {
+			t.Errorf("Write-only token shouldn't be read into state, got %s and %s", data.Token, data.AgentToken)
+		}
 	})
 }
 
@@ -95,6 +139,7 @@ type mockAgentInstall struct {
 	statusErr     error
 	preInstallErr error
 	installErr    error
+	notReady      error
 }
 
 func (m mockAgentInstall) Status(ssh_client.SSHClient) (bool, error) {
@@ -108,6 +153,10 @@ func (m mockAgentInstall) Preinstall(ssh_client.SSHClient) error {
 	return m.preInstallErr
 }
 
+func (m mockAgentInstall) WaitForReady(ssh_client.SSHClient, string, time.Duration) error {
+	return m.notReady
+}
+
 func TestAgentHandlerCreate(t *testing.T) {
 	t.Parallel()
 
@@ -166,4 +215,66 @@ func TestAgentHandlerCreate(t *testing.T) {
 			t.Errorf("Expected `agent,192.168.1.1` for id, got %s", data.Id)
 		}
 	})
+
+	t.Run("Not ready", func(t *testing.T) {
+		data := handlers.AgentClientModel{
+			WaitForReady: (&handlers.WaitForReady{Timeout: types.StringValue("1m")}).ToObject(t.Context()),
+		}
+		ssh := mockSSH{
+			hostname: "192.168.1.1",
+		}
+		err := data.Create(t.Context(), &mockKubeconfigGoodSSH{mockSSH: &ssh}, &mockAgentInstall{status: true, notReady: fmt.Errorf("node is not Ready")})
+		if err == nil {
+			t.Errorf("Node that isn't ready should raise")
+		}
+	})
+}
+
+func TestAgentHandlerReadDrift(t *testing.T) {
+	t.Parallel()
+
+	data := handlers.AgentClientModel{K3sConfig: handlers.NewYamlValue("node-label:\n- pool=workers\n")}
+	err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockAgent{
+		config:       map[any]any{"node-label": []any{"pool=workers"}},
+		remoteConfig: map[any]any{"node-label": []any{"pool=gpu"}},
+	})
+	if err != nil {
+		t.Fatalf("Good read shouldn't raise, got %s", err.Error())
+	}
+	if !data.K3sConfig.Equal(handlers.NewYamlValue("node-label:\n- pool=gpu\n")) {
+		t.Errorf("Expected remote config, got %s", data.K3sConfig)
+	}
+}
+
+func TestAgentHandlerImport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Bad resync", func(t *testing.T) {
+		var data handlers.AgentClientModel
+		err := data.Import(t.Context(), &mockKubeconfigGoodSSH{}, &mockAgent{resync: fmt.Errorf("error")})
+		if err == nil {
+			t.Errorf("Bad resync should raise, got nil")
+		}
+	})
+
+	t.Run("Good import", func(t *testing.T) {
+		var data handlers.AgentClientModel
+		ssh := mockSSH{hostname: "192.168.1.3"}
+		err := data.Import(t.Context(), &mockKubeconfigGoodSSH{&ssh}, &mockAgent{
+			status:       true,
+			remoteConfig: map[any]any{"node-label": []any{"pool=workers"}},
+		})
+		if err != nil {
+			t.Fatalf("Good import shouldn't raise, got %s", err.Error())
+		}
+		if !data.K3sConfig.Equal(handlers.NewYamlValue("node-label:\n- pool=workers\n")) {
+			t.Errorf("Expected config to be imported, got %s", data.K3sConfig)
+		}
+		if !data.Token.Equal(types.StringValue("token")) || !data.AgentToken.IsNull() {
+			t.Errorf("Expected the token to be imported as the server token, got %s %s", data.Token, data.AgentToken)
+		}
+		if !data.Id.Equal(types.StringValue("agent,192.168.1.3")) {
+			t.Errorf("Expected id agent,192.168.1.3, got %s", data.Id)
+		}
+	})
 }
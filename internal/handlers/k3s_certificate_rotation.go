@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+// Services accepted by `k3s certificate rotate --service`.
+var CertificateServices = []string{
+	"admin",
+	"api-server",
+	"auth-proxy",
+	"cloud-controller",
+	"controller-manager",
+	"etcd",
+	"k3s-controller",
+	"k3s-server",
+	"kube-proxy",
+	"kubelet",
+	"scheduler",
+	"supervisor",
+}
+
+type CertificateRotationClientModel struct {
+	Auth     types.Object `tfsdk:"auth"`
+	Services types.List   `tfsdk:"services"`
+	Triggers types.Map    `tfsdk:"triggers"`
+	// Outputs
+	Id                types.String `tfsdk:"id"`
+	KubeConfig        types.String `tfsdk:"kubeconfig"`
+	ClusterAuth       types.Object `tfsdk:"cluster_auth"`
+	CertificateExpiry types.Map    `tfsdk:"certificate_expiry"`
+}
+
+func (c CertificateRotationClientModel) Validate(ctx context.Context) error {
+	var services []string
+	c.Services.ElementsAs(ctx, &services, false)
+	for _, service := range services {
+		if !slices.Contains(CertificateServices, service) {
+			return fmt.Errorf("unknown service %s, expected one of %v", service, CertificateServices)
+		}
+	}
+	return nil
+}
+
+type TCertificateRotation interface {
+	k3s.ServerCertificates
+	k3s.ServerKubeconfig
+}
+
+func (c *CertificateRotationClientModel) Create(
+	ctx context.Context,
+	auth K3sTypeSSH,
+	server TCertificateRotation,
+) error {
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+	tflog.Debug(ctx, "k3s certificate rotation ssh client created")
+
+	var services []string
+	c.Services.ElementsAs(ctx, &services, false)
+
+	if err := server.RotateCertificates(sshClient, services); err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "k3s server certificates rotated")
+
+	clusterAuth, err := BuildClusterAuth(server.KubeConfig())
+	if err != nil {
+		return fmt.Errorf("fetching cluster auth: %s", err.Error())
+	}
+	if err := clusterAuth.UpdateHost(sshClient.HostnameOrIpAddress()); err != nil {
+		return fmt.Errorf("updating kubeconfig host: %s", err.Error())
+	}
+
+	certificateExpiry, err := server.CertificateExpiry(sshClient)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "k3s server certificate expiry fetched")
+
+	c.Id = types.StringValue(fmt.Sprintf("certificate-rotation,%s", sshClient.HostnameOrIpAddress()))
+	c.ClusterAuth = clusterAuth.ToObject(ctx)
+	c.KubeConfig = types.StringValue(clusterAuth.KubeConfig())
+	c.CertificateExpiry, _ = types.MapValueFrom(ctx, types.StringType, certificateExpiry)
+
+	return nil
+}
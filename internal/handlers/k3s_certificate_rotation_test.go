@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+type mockCertificates struct {
+	mockServer
+	rotateErr error
+	services  []string
+}
+
+func (m *mockCertificates) RotateCertificates(_ ssh_client.SSHClient, services []string) error {
+	m.services = services
+	return m.rotateErr
+}
+
+func TestCertificateRotationCreate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Bad Ssh", func(t *testing.T) {
+		var data handlers.CertificateRotationClientModel
+		if err := data.Create(t.Context(), &mockKubeConfigBadSSH{}, &mockCertificates{}); err == nil {
+			t.Errorf("Bad ssh should raise, got nil")
+		}
+	})
+
+	t.Run("Bad rotation", func(t *testing.T) {
+		data := handlers.CertificateRotationClientModel{Services: types.ListNull(types.StringType)}
+		if err := data.Create(t.Context(), &mockKubeconfigGoodSSH{}, &mockCertificates{rotateErr: fmt.Errorf("error")}); err == nil {
+			t.Errorf("Bad rotation should raise, got nil")
+		}
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		services, _ := types.ListValueFrom(t.Context(), types.StringType, []string{"api-server", "kubelet"})
+		data := handlers.CertificateRotationClientModel{Services: services}
+		ssh := mockSSH{}
+		server := mockCertificates{}
+		if err := data.Create(t.Context(), &mockKubeconfigGoodSSH{&ssh}, &server); err != nil {
+			t.Fatalf("Good rotation shouldn't raise, got %s", err.Error())
+		}
+		if fmt.Sprint(server.services) != "[api-server kubelet]" {
+			t.Errorf("Expected api-server and kubelet to be rotated, got %v", server.services)
+		}
+		if data.KubeConfig.IsNull() || data.ClusterAuth.IsNull() {
+			t.Errorf("Expected kubeconfig and cluster auth to be refreshed")
+		}
+		if len(data.CertificateExpiry.Elements()) != 1 {
+			t.Errorf("Expected certificate expiry to be set, got %v", data.CertificateExpiry)
+		}
+	})
+}
+
+func TestCertificateRotationValidate(t *testing.T) {
+	t.Parallel()
+
+	services, _ := types.ListValueFrom(t.Context(), types.StringType, []string{"api-server"})
+	if err := (handlers.CertificateRotationClientModel{Services: services}).Validate(t.Context()); err != nil {
+		t.Errorf("Known service shouldn't raise, got %s", err.Error())
+	}
+
+	services, _ = types.ListValueFrom(t.Context(), types.StringType, []string{"apiserver"})
+	if err := (handlers.CertificateRotationClientModel{Services: services}).Validate(t.Context()); err == nil {
+		t.Errorf("Unknown service should raise, got nil")
+	}
+}
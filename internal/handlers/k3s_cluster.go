@@ -0,0 +1,708 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+type ClusterModel struct {
+	// Nodes, the first server initializes the cluster
+	Servers types.List `tfsdk:"servers"`
+	Agents  types.List `tfsdk:"agents"`
+	// Shared by every node
+	BinDir       types.String `tfsdk:"bin_dir"`
+	K3sRegistry  YamlValue    `tfsdk:"registry"`
+	WaitForReady types.Object `tfsdk:"wait_for_ready"`
+	// Nodes operated on at once
+	Parallelism types.Int64 `tfsdk:"parallelism"`
+	// Agents restarted at once by a rolling update
+	UpdateBatchSize types.Int64 `tfsdk:"update_batch_size"`
+	// Outputs
+	Id          types.String `tfsdk:"id"`
+	Server      types.String `tfsdk:"server"`
+	KubeConfig  types.String `tfsdk:"kubeconfig"`
+	Token       types.String `tfsdk:"token"`
+	AgentToken  types.String `tfsdk:"agent_token"`
+	Active      types.Bool   `tfsdk:"active"`
+	ClusterAuth types.Object `tfsdk:"cluster_auth"`
+	// Deadlines of each operation
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+
+	version string
+}
+
+// A server or agent of a k3s_cluster.
+type ClusterNode struct {
+	Name      types.String `tfsdk:"name"`
+	Auth      types.Object `tfsdk:"auth"`
+	K3sConfig YamlValue    `tfsdk:"config"`
+}
+
+func (ClusterNode) NestedObject() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the node within the cluster, nodes are matched by name between applies",
+			},
+			"auth": NodeAuth{}.ClusterNodeSchema(),
+			"config": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				CustomType:          YamlType{},
+				MarkdownDescription: "K3s config of the node",
+				PlanModifiers: []planmodifier.String{
+					YamlUseStateWhenEqual(),
+				},
+			},
+		},
+	}
+}
+
+func (ClusterNode) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"name":   types.StringType,
+		"auth":   types.ObjectType{AttrTypes: NodeAuth{}.AttributeTypes()},
+		"config": YamlType{},
+	}
+}
+
+func (n ClusterNode) host(ctx context.Context) string {
+	return NewNodeAuth(ctx, n.Auth).Host.ValueString()
+}
+
+// Same node when both the name and host match, a node moved to another
+// host is a new node.
+func (n ClusterNode) sameNode(ctx context.Context, other ClusterNode) bool {
+	return n.Name.Equal(other.Name) && n.host(ctx) == other.host(ctx)
+}
+
+func (c *ClusterModel) SetVersion(version *string) {
+	if version != nil {
+		c.version = *version
+	}
+}
+
+type TClusterServer interface {
+	TServerCreate
+	TServerUpdate
+	TK3sServerRead
+	k3s.ComponentUninstall
+}
+
+type TClusterAgent interface {
+	TK3sAgentCreate
+	TK3sAgentUpdate
+	TK3sAgentRead
+	k3s.ComponentUninstall
+}
+
+// Builds what the cluster needs for each of its nodes.
+type TClusterComponents interface {
+	Auth(ctx context.Context, auth types.Object) TServerSSH
+	Server(ctx context.Context, model *ServerClientModel) (TClusterServer, error)
+	Agent(ctx context.Context, model *AgentClientModel) (TClusterAgent, error)
+	NodeRemoval(ctx context.Context, kubeconfig string) (k3s.NodeRemoval, error)
+}
+
+func (c ClusterModel) nodes(ctx context.Context) (servers []ClusterNode, agents []ClusterNode, err error) {
+	if diags := c.Servers.ElementsAs(ctx, &servers, false); diags.HasError() {
+		return nil, nil, fmt.Errorf("reading servers")
+	}
+	if !c.Agents.IsNull() && !c.Agents.IsUnknown() {
+		if diags := c.Agents.ElementsAs(ctx, &agents, false); diags.HasError() {
+			return nil, nil, fmt.Errorf("reading agents")
+		}
+	}
+	return servers, agents, nil
+}
+
+func (c *ClusterModel) setNodes(ctx context.Context, servers []ClusterNode, agents []ClusterNode) {
+	nodeType := types.ObjectType{AttrTypes: ClusterNode{}.AttributeTypes()}
+	c.Servers, _ = types.ListValueFrom(ctx, nodeType, servers)
+	if len(agents) > 0 || !c.Agents.IsNull() {
+		c.Agents, _ = types.ListValueFrom(ctx, nodeType, agents)
+	}
+}
+
+// Fills in the port every node connects on, which is computed from the
+// provider's default_auth when left unset.
+func (c *ClusterModel) ResolvePorts(ctx context.Context, defaults *DefaultAuth) error {
+	servers, agents, err := c.nodes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, nodes := range [][]ClusterNode{servers, agents} {
+		for i := range nodes {
+			nodes[i].Auth = NewNodeAuth(ctx, nodes[i].Auth).WithDefaults(defaults).StateObject(ctx)
+		}
+	}
+	c.setNodes(ctx, servers, agents)
+	return nil
+}
+
+func (c ClusterModel) Validate(ctx context.Context) error {
+	if c.Servers.IsUnknown() || c.Agents.IsUnknown() {
+		return nil
+	}
+	servers, agents, err := c.nodes(ctx)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("at least one server is needed to initialize the cluster")
+	}
+	if !c.Parallelism.IsNull() && !c.Parallelism.IsUnknown() && c.Parallelism.ValueInt64() < 1 {
+		return fmt.Errorf("parallelism must be at least 1, got %d", c.Parallelism.ValueInt64())
+	}
+	if !c.UpdateBatchSize.IsNull() && !c.UpdateBatchSize.IsUnknown() && c.UpdateBatchSize.ValueInt64() < 1 {
+		return fmt.Errorf("update_batch_size must be at least 1, got %d", c.UpdateBatchSize.ValueInt64())
+	}
+
+	var names, hosts []string
+	for _, node := range append(servers, agents...) {
+		if node.Name.IsUnknown() || node.Auth.IsUnknown() {
+			continue
+		}
+		name := node.Name.ValueString()
+		if slices.Contains(names, name) {
+			return fmt.Errorf("node name %s is used more than once", name)
+		}
+		names = append(names, name)
+
+		auth := NewNodeAuth(ctx, node.Auth)
+		if err := auth.Validate(); err != nil {
+			return fmt.Errorf("node %s: %s", name, err.Error())
+		}
+		if auth.Host.IsUnknown() {
+			continue
+		}
+		if slices.Contains(hosts, auth.Host.ValueString()) {
+			return fmt.Errorf("host %s of node %s is used by another node", auth.Host.ValueString(), name)
+		}
+		hosts = append(hosts, auth.Host.ValueString())
+	}
+	return nil
+}
+
+// The server model of a node. The init server starts the embedded etcd
+// cluster, every other server joins it.
+func (c ClusterModel) serverModel(ctx context.Context, node ClusterNode, init bool) ServerClientModel {
+	ha := HaConfig{
+		ClusterInit:    types.BoolValue(init),
+		Token:          types.StringNull(),
+		AgentToken:     types.StringNull(),
+		Server:         types.StringNull(),
+		TokenWo:        types.StringNull(),
+		TokenWoVersion: types.Int64Null(),
+	}
+	if !init {
+		ha.Token = c.Token
+		ha.Server = c.Server
+	}
+
+	return ServerClientModel{
+		Auth:            node.Auth,
+		AuthWo:          types.ObjectNull(AuthWriteOnly{}.AttributeTypes()),
+		AuthWoVersion:   types.Int64Null(),
+		BinDir:          c.BinDir,
+		K3sConfig:       node.K3sConfig,
+		K3sRegistry:     c.K3sRegistry,
+		ServerConfig:    types.ObjectNull(ServerConfig{}.AttributeTypes()),
+		HaConfig:        ha.ToObject(ctx),
+		OidcConfig:      types.ObjectNull(OidcConfig{}.AttributeTypes()),
+		DatastoreConfig: types.ObjectNull(DatastoreConfig{}.AttributeTypes()),
+		WaitForReady:    c.WaitForReady,
+		Timeouts:        c.Timeouts,
+		version:         c.version,
+	}
+}
+
+// The agent model of a node, joined with the agent only token of the cluster.
+func (c ClusterModel) agentModel(node ClusterNode) AgentClientModel {
+	return AgentClientModel{
+		Auth:           node.Auth,
+		AuthWo:         types.ObjectNull(AuthWriteOnly{}.AttributeTypes()),
+		AuthWoVersion:  types.Int64Null(),
+		Server:         c.Server,
+		BinDir:         c.BinDir,
+		KubeConfig:     c.KubeConfig,
+		K3sRegistry:    c.K3sRegistry,
+		K3sConfig:      node.K3sConfig,
+		AgentConfig:    types.ObjectNull(AgentConfig{}.AttributeTypes()),
+		Token:          types.StringNull(),
+		AgentToken:     c.AgentToken,
+		TokenWo:        types.StringNull(),
+		TokenWoVersion: types.Int64Null(),
+		AllowDeleteErr: types.BoolValue(true),
+		WaitForReady:   c.WaitForReady,
+		Timeouts:       c.Timeouts,
+		version:        c.version,
+	}
+}
+
+// Runs fn on every node, at most `parallelism` at once. The error of each
+// node that failed is returned, prefixed with the node name.
+func (c ClusterModel) inParallel(ctx context.Context, nodes []ClusterNode, fn func(int, ClusterNode) error) error {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.Name.ValueString()
+	}
+	return c.pool(ctx).Run(names, func(i int) error {
+		return fn(i, nodes[i])
+	})
+}
+
+func (c ClusterModel) pool(ctx context.Context) *k3s.NodePool {
+	return k3s.NewNodePool(ctx, int(c.Parallelism.ValueInt64()))
+}
+
+func (c *ClusterModel) createServer(ctx context.Context, node ClusterNode, init bool, components TClusterComponents) (ServerClientModel, error) {
+	model := c.serverModel(ctx, node, init)
+	server, err := components.Server(ctx, &model)
+	if err != nil {
+		return model, fmt.Errorf("building server %s: %s", node.Name.ValueString(), err.Error())
+	}
+	if err := model.Create(ctx, components.Auth(ctx, node.Auth), server); err != nil {
+		return model, fmt.Errorf("creating server %s: %s", node.Name.ValueString(), err.Error())
+	}
+	tflog.Debug(ctx, fmt.Sprintf("k3s cluster server %s created", node.Name.ValueString()))
+	return model, nil
+}
+
+func (c *ClusterModel) createAgent(ctx context.Context, node ClusterNode, components TClusterComponents) (AgentClientModel, error) {
+	model := c.agentModel(node)
+	agent, err := components.Agent(ctx, &model)
+	if err != nil {
+		return model, fmt.Errorf("building agent: %s", err.Error())
+	}
+	if err := model.Create(ctx, components.Auth(ctx, node.Auth), agent); err != nil {
+		return model, fmt.Errorf("creating agent: %s", err.Error())
+	}
+	tflog.Debug(ctx, fmt.Sprintf("k3s cluster agent %s created", node.Name.ValueString()))
+	return model, nil
+}
+
+// Installs agents in parallel, returning the ones that were created.
+func (c *ClusterModel) createAgents(ctx context.Context, nodes []ClusterNode, components TClusterComponents) ([]ClusterNode, bool, error) {
+	created := make([]bool, len(nodes))
+	active := make([]bool, len(nodes))
+	err := c.inParallel(ctx, nodes, func(i int, node ClusterNode) error {
+		model, err := c.createAgent(ctx, node, components)
+		if err != nil {
+			return err
+		}
+		created[i], active[i] = true, model.Active.ValueBool()
+		return nil
+	})
+
+	var agents []ClusterNode
+	for i, node := range nodes {
+		if created[i] {
+			agents = append(agents, node)
+		}
+	}
+	return agents, !slices.Contains(active, false), err
+}
+
+// Creates the cluster: the init server first, then the remaining servers one
+// at a time so etcd keeps quorum as members join, then the agents in parallel.
+// When a node fails, only the nodes that were created are kept in the model.
+func (c *ClusterModel) Create(ctx context.Context, components TClusterComponents) error {
+	servers, agents, err := c.nodes(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.Validate(ctx); err != nil {
+		return err
+	}
+
+	init, err := c.createServer(ctx, servers[0], true, components)
+	if err != nil {
+		return err
+	}
+	c.Id = types.StringValue(fmt.Sprintf("cluster,%s", servers[0].host(ctx)))
+	c.Server = init.Server
+	c.Token = init.Token
+	c.AgentToken = init.AgentToken
+	c.KubeConfig = init.KubeConfig
+	c.ClusterAuth = init.ClusterAuth
+	active := init.Active.ValueBool()
+
+	for i, node := range servers[1:] {
+		model, err := c.createServer(ctx, node, false, components)
+		if err != nil {
+			c.setNodes(ctx, servers[:i+1], nil)
+			return err
+		}
+		active = active && model.Active.ValueBool()
+	}
+
+	created, agentsActive, err := c.createAgents(ctx, agents, components)
+	c.setNodes(ctx, servers, created)
+	if err != nil {
+		return err
+	}
+
+	c.Active = types.BoolValue(active && agentsActive)
+	return nil
+}
+
+// Refreshes the health of every node. A node that can't be read is
+// reported as inactive rather than failing the whole cluster.
+func (c *ClusterModel) Read(ctx context.Context, components TClusterComponents) error {
+	servers, agents, err := c.nodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	serversActive := make([]bool, len(servers))
+	agentsActive := make([]bool, len(agents))
+	_ = c.inParallel(ctx, servers, func(i int, node ClusterNode) error {
+		model := c.serverModel(ctx, node, i == 0)
+		server, err := components.Server(ctx, &model)
+		if err == nil {
+			err = model.Read(ctx, components.Auth(ctx, node.Auth), server)
+		}
+		if err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("reading server %s: %s", node.Name.ValueString(), err.Error()))
+			return nil
+		}
+		serversActive[i] = model.Active.ValueBool()
+		return nil
+	})
+	_ = c.inParallel(ctx, agents, func(i int, node ClusterNode) error {
+		model := c.agentModel(node)
+		agent, err := components.Agent(ctx, &model)
+		if err == nil {
+			err = model.Read(ctx, components.Auth(ctx, node.Auth), agent)
+		}
+		if err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("reading agent %s: %s", node.Name.ValueString(), err.Error()))
+			return nil
+		}
+		agentsActive[i] = model.Active.ValueBool()
+		return nil
+	})
+	tflog.Debug(ctx, "k3s cluster nodes read")
+
+	c.Active = types.BoolValue(!slices.Contains(serversActive, false) && !slices.Contains(agentsActive, false))
+	return nil
+}
+
+type clusterNodeChange struct {
+	existing ClusterNode
+	inc      ClusterNode
+}
+
+// Splits the nodes of `inc` into the ones to add, the ones to update in
+// place and, from `existing`, the ones to remove.
+func diffNodes(ctx context.Context, existing []ClusterNode, inc []ClusterNode) (added []ClusterNode, kept []clusterNodeChange, removed []ClusterNode) {
+	for _, node := range inc {
+		i := slices.IndexFunc(existing, func(e ClusterNode) bool { return e.sameNode(ctx, node) })
+		if i < 0 {
+			added = append(added, node)
+			continue
+		}
+		kept = append(kept, clusterNodeChange{existing: existing[i], inc: node})
+	}
+	for _, node := range existing {
+		if !slices.ContainsFunc(inc, func(i ClusterNode) bool { return i.sameNode(ctx, node) }) {
+			removed = append(removed, node)
+		}
+	}
+	return
+}
+
+// Nodes that restart with the change, because their config or the shared
+// registry changed.
+func (c ClusterModel) changedNodes(inc ClusterModel, kept []clusterNodeChange) (changed []clusterNodeChange) {
+	for _, change := range kept {
+		if !change.existing.K3sConfig.semanticEqual(change.inc.K3sConfig) || !c.K3sRegistry.semanticEqual(inc.K3sRegistry) {
+			changed = append(changed, change)
+		}
+	}
+	return
+}
+
+func changeNames(changes []clusterNodeChange) []string {
+	names := make([]string, len(changes))
+	for i, change := range changes {
+		names[i] = change.inc.Name.ValueString()
+	}
+	return names
+}
+
+func (c ClusterModel) updateServer(ctx context.Context, inc ClusterModel, change clusterNodeChange, init bool, components TClusterComponents) error {
+	existing := c.serverModel(ctx, change.existing, init)
+	model := inc.serverModel(ctx, change.inc, init)
+	server, err := components.Server(ctx, &model)
+	if err != nil {
+		return fmt.Errorf("building server: %s", err.Error())
+	}
+	auth := components.Auth(ctx, change.inc.Auth)
+	if err := existing.Update(ctx, model, auth, server); err != nil {
+		return fmt.Errorf("updating server: %s", err.Error())
+	}
+	return inc.healthy(ctx, auth, existing.Active, server)
+}
+
+func (c ClusterModel) updateAgent(ctx context.Context, inc ClusterModel, change clusterNodeChange, components TClusterComponents) error {
+	existing := c.agentModel(change.existing)
+	model := inc.agentModel(change.inc)
+	agent, err := components.Agent(ctx, &model)
+	if err != nil {
+		return fmt.Errorf("building agent: %s", err.Error())
+	}
+	auth := components.Auth(ctx, change.inc.Auth)
+	if err := existing.Update(ctx, model, auth, agent); err != nil {
+		return fmt.Errorf("updating agent: %s", err.Error())
+	}
+	return inc.healthy(ctx, auth, existing.Active, agent)
+}
+
+// Gates a rolling update on the restarted node being active and Ready in
+// kubernetes, within the `wait_for_ready` timeout. Updates are always gated,
+// without `wait_for_ready` the default timeout applies.
+func (c ClusterModel) healthy(ctx context.Context, auth K3sTypeSSH, active types.Bool, node k3s.ComponentWaitForReady) error {
+	if !active.ValueBool() {
+		return fmt.Errorf("k3s isn't active after the restart")
+	}
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+	if err := node.WaitForReady(sshClient, c.KubeConfig.ValueString(), NewWaitForReady(ctx, c.WaitForReady).timeout()); err != nil {
+		return fmt.Errorf("waiting for the node to be ready: %s", err.Error())
+	}
+	tflog.Debug(ctx, fmt.Sprintf("k3s cluster node %s healthy after the update", sshClient.HostnameOrIpAddress()))
+	return nil
+}
+
+// Cordons and drains the node, then removes it from the cluster, so its
+// workloads are rescheduled before k3s is uninstalled.
+func drainNode(ctx context.Context, auth K3sTypeSSH, removal k3s.NodeRemoval) error {
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+	hostname, err := sshClient.Hostname()
+	if err != nil {
+		return fmt.Errorf("fetching hostname: %s", err.Error())
+	}
+	return removal.RemoveNode(hostname)
+}
+
+// Applies the node changes of `inc`. New nodes join first, so the cluster
+// never shrinks below what it was, then existing nodes take their new config
+// in a rolling update and removed nodes are drained and uninstalled. A node
+// that isn't healthy after its restart halts the update. The init server bootstraps
+// the cluster and can't be replaced.
+func (c *ClusterModel) Update(ctx context.Context, inc ClusterModel, components TClusterComponents) error {
+	servers, agents, err := c.nodes(ctx)
+	if err != nil {
+		return err
+	}
+	incServers, incAgents, err := inc.nodes(ctx)
+	if err != nil {
+		return err
+	}
+	if err := inc.Validate(ctx); err != nil {
+		return err
+	}
+	if !servers[0].sameNode(ctx, incServers[0]) {
+		return fmt.Errorf("the init server %s can't be renamed or moved, it bootstraps the cluster", servers[0].Name.ValueString())
+	}
+
+	// What the cluster put out doesn't change with its nodes
+	inc.Server = c.Server
+	inc.Token = c.Token
+	inc.AgentToken = c.AgentToken
+	inc.KubeConfig = c.KubeConfig
+	inc.version = c.version
+
+	addedServers, keptServers, removedServers := diffNodes(ctx, servers, incServers)
+	addedAgents, keptAgents, removedAgents := diffNodes(ctx, agents, incAgents)
+	active := true
+
+	// Nodes are tracked as they change, so when a step fails the model still
+	// holds every node that is installed, and none that were removed
+	failed := func(err error) error {
+		c.setNodes(ctx, servers, agents)
+		c.Active = types.BoolValue(false)
+		return err
+	}
+
+	for _, node := range addedServers {
+		model, err := inc.createServer(ctx, node, false, components)
+		if err != nil {
+			return failed(err)
+		}
+		servers = append(servers, node)
+		active = active && model.Active.ValueBool()
+	}
+
+	created, agentsActive, err := inc.createAgents(ctx, addedAgents, components)
+	agents = append(agents, created...)
+	if err != nil {
+		return failed(err)
+	}
+	active = active && agentsActive
+
+	// Servers restart one at a time so etcd keeps quorum, agents in batches
+	changedServers := c.changedNodes(inc, keptServers)
+	updated := make([]bool, len(changedServers))
+	err = inc.pool(ctx).Rolling(changeNames(changedServers), 1, func(i int) error {
+		if err := c.updateServer(ctx, inc, changedServers[i], changedServers[i].existing.sameNode(ctx, servers[0]), components); err != nil {
+			return err
+		}
+		updated[i] = true
+		return nil
+	})
+	servers = replaceNodes(ctx, servers, changedServers, updated)
+	if err != nil {
+		return failed(fmt.Errorf("rolling servers: %s", err.Error()))
+	}
+
+	changedAgents := c.changedNodes(inc, keptAgents)
+	updated = make([]bool, len(changedAgents))
+	err = inc.pool(ctx).Rolling(changeNames(changedAgents), int(inc.UpdateBatchSize.ValueInt64()), func(i int) error {
+		if err := c.updateAgent(ctx, inc, changedAgents[i], components); err != nil {
+			return err
+		}
+		updated[i] = true
+		return nil
+	})
+	agents = replaceNodes(ctx, agents, changedAgents, updated)
+	if err != nil {
+		return failed(fmt.Errorf("rolling agents: %s", err.Error()))
+	}
+	tflog.Debug(ctx, "k3s cluster nodes added and updated")
+
+	if len(removedServers)+len(removedAgents) > 0 {
+		removal, err := components.NodeRemoval(ctx, c.KubeConfig.ValueString())
+		if err != nil {
+			return failed(fmt.Errorf("connecting to the cluster: %s", err.Error()))
+		}
+		for _, node := range removedAgents {
+			if err := c.removeAgent(ctx, node, removal, components); err != nil {
+				return failed(err)
+			}
+			agents = withoutNode(ctx, agents, node)
+		}
+		for _, node := range slices.Backward(removedServers) {
+			if err := c.removeServer(ctx, node, removal, components); err != nil {
+				return failed(err)
+			}
+			servers = withoutNode(ctx, servers, node)
+		}
+	}
+
+	c.Servers = inc.Servers
+	c.Agents = inc.Agents
+	c.K3sRegistry = inc.K3sRegistry
+	c.WaitForReady = inc.WaitForReady
+	c.Parallelism = inc.Parallelism
+	c.UpdateBatchSize = inc.UpdateBatchSize
+	c.Active = types.BoolValue(active)
+	return nil
+}
+
+// Swaps in the new definition of each node that was updated.
+func replaceNodes(ctx context.Context, nodes []ClusterNode, changes []clusterNodeChange, updated []bool) []ClusterNode {
+	for i, change := range changes {
+		if !updated[i] {
+			continue
+		}
+		if j := slices.IndexFunc(nodes, func(n ClusterNode) bool { return n.sameNode(ctx, change.existing) }); j >= 0 {
+			nodes[j] = change.inc
+		}
+	}
+	return nodes
+}
+
+func withoutNode(ctx context.Context, nodes []ClusterNode, node ClusterNode) []ClusterNode {
+	return slices.DeleteFunc(nodes, func(n ClusterNode) bool { return n.sameNode(ctx, node) })
+}
+
+func (c *ClusterModel) removeAgent(ctx context.Context, node ClusterNode, removal k3s.NodeRemoval, components TClusterComponents) error {
+	model := c.agentModel(node)
+	auth := components.Auth(ctx, node.Auth)
+	if err := drainNode(ctx, auth, removal); err != nil {
+		return fmt.Errorf("draining agent %s: %s", node.Name.ValueString(), err.Error())
+	}
+	agent, err := components.Agent(ctx, &model)
+	if err != nil {
+		return fmt.Errorf("building agent %s: %s", node.Name.ValueString(), err.Error())
+	}
+	if err := model.Delete(ctx, auth, agent); err != nil {
+		return fmt.Errorf("removing agent %s: %s", node.Name.ValueString(), err.Error())
+	}
+	tflog.Debug(ctx, fmt.Sprintf("k3s cluster agent %s removed", node.Name.ValueString()))
+	return nil
+}
+
+func (c *ClusterModel) removeServer(ctx context.Context, node ClusterNode, removal k3s.NodeRemoval, components TClusterComponents) error {
+	model := c.serverModel(ctx, node, false)
+	auth := components.Auth(ctx, node.Auth)
+	if err := drainNode(ctx, auth, removal); err != nil {
+		return fmt.Errorf("draining server %s: %s", node.Name.ValueString(), err.Error())
+	}
+	server, err := components.Server(ctx, &model)
+	if err != nil {
+		return fmt.Errorf("building server %s: %s", node.Name.ValueString(), err.Error())
+	}
+	if err := model.Delete(ctx, auth, server); err != nil {
+		return fmt.Errorf("removing server %s: %s", node.Name.ValueString(), err.Error())
+	}
+	tflog.Debug(ctx, fmt.Sprintf("k3s cluster server %s removed", node.Name.ValueString()))
+	return nil
+}
+
+// Uninstalls every node: the agents in parallel, then the servers in the
+// reverse order they joined, the init server last. Every node is tried
+// even when others fail.
+func (c *ClusterModel) Delete(ctx context.Context, components TClusterComponents) error {
+	servers, agents, err := c.nodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{c.inParallel(ctx, agents, func(_ int, node ClusterNode) error {
+		model := c.agentModel(node)
+		agent, err := components.Agent(ctx, &model)
+		if err != nil {
+			return fmt.Errorf("building agent: %s", err.Error())
+		}
+		if err := model.Delete(ctx, components.Auth(ctx, node.Auth), agent); err != nil {
+			return fmt.Errorf("deleting agent: %s", err.Error())
+		}
+		return nil
+	})}
+	tflog.Debug(ctx, "k3s cluster agents deleted")
+
+	for i, node := range slices.Backward(servers) {
+		model := c.serverModel(ctx, node, i == 0)
+		server, err := components.Server(ctx, &model)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("building server %s: %s", node.Name.ValueString(), err.Error()))
+			continue
+		}
+		if err := model.Delete(ctx, components.Auth(ctx, node.Auth), server); err != nil {
+			errs = append(errs, fmt.Errorf("deleting server %s: %s", node.Name.ValueString(), err.Error()))
+		}
+	}
+	tflog.Debug(ctx, "k3s cluster servers deleted")
+
+	return errors.Join(errs...)
+}
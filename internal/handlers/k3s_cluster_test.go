@@ -0,0 +1,537 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+// What the nodes of a cluster went through. Agents run in parallel, so
+// events are recorded under a lock.
+type clusterEvents struct {
+	mu     sync.Mutex
+	events []string
+	// Event that fails instead of being recorded
+	failOn string
+	// Host of each server mapped to the server it joined
+	joined map[string]string
+}
+
+func (e *clusterEvents) record(event string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if event == e.failOn {
+		return fmt.Errorf("%s failed", event)
+	}
+	e.events = append(e.events, event)
+	return nil
+}
+
+func (e *clusterEvents) index(event string) int {
+	return slices.Index(e.events, event)
+}
+
+type mockClusterServer struct {
+	k3s.Server
+	events *clusterEvents
+	host   string
+}
+
+func (m mockClusterServer) Preinstall(ssh_client.SSHClient) error { return nil }
+func (m mockClusterServer) Install(ssh_client.SSHClient) error {
+	return m.events.record("install server " + m.host)
+}
+func (m mockClusterServer) Update(ssh_client.SSHClient) error {
+	return m.events.record("update server " + m.host)
+}
+func (m mockClusterServer) Uninstall(ssh_client.SSHClient, string, ...bool) error {
+	return m.events.record("uninstall server " + m.host)
+}
+func (m mockClusterServer) Resync(ssh_client.SSHClient) error {
+	return m.events.record("resync server " + m.host)
+}
+func (m mockClusterServer) WaitForReady(ssh_client.SSHClient, string, time.Duration) error {
+	return m.events.record("ready server " + m.host)
+}
+func (mockClusterServer) Status(ssh_client.SSHClient) (bool, error) { return true, nil }
+func (mockClusterServer) KubeConfig() string                        { return TestMockKubeconfig }
+func (mockClusterServer) Token() string                             { return "token" }
+func (mockClusterServer) AgentToken() string                        { return "agent-token" }
+func (mockClusterServer) Config() map[any]any                       { return nil }
+func (mockClusterServer) Registry() map[any]any                     { return nil }
+func (mockClusterServer) CertificateExpiry(ssh_client.SSHRun) (map[string]string, error) {
+	return nil, nil
+}
+
+type mockClusterAgent struct {
+	k3s.Agent
+	events *clusterEvents
+	host   string
+}
+
+func (m mockClusterAgent) Preinstall(ssh_client.SSHClient) error { return nil }
+func (m mockClusterAgent) Install(ssh_client.SSHClient) error {
+	return m.events.record("install agent " + m.host)
+}
+func (m mockClusterAgent) Update(ssh_client.SSHClient) error {
+	return m.events.record("update agent " + m.host)
+}
+func (m mockClusterAgent) Uninstall(ssh_client.SSHClient, string, ...bool) error {
+	return m.events.record("uninstall agent " + m.host)
+}
+func (m mockClusterAgent) Resync(ssh_client.SSHClient) error {
+	return m.events.record("resync agent " + m.host)
+}
+func (m mockClusterAgent) WaitForReady(ssh_client.SSHClient, string, time.Duration) error {
+	return m.events.record("ready agent " + m.host)
+}
+func (mockClusterAgent) Status(ssh_client.SSHClient) (bool, error) { return true, nil }
+func (mockClusterAgent) Server() string                            { return "" }
+func (mockClusterAgent) Token() string                             { return "agent-token" }
+func (mockClusterAgent) Config() map[any]any                       { return nil }
+func (mockClusterAgent) Registry() map[any]any                     { return nil }
+
+type mockNodeRemoval struct {
+	events *clusterEvents
+}
+
+func (m mockNodeRemoval) RemoveNode(hostname string) error {
+	return m.events.record("drain " + hostname)
+}
+
+type mockClusterComponents struct {
+	events *clusterEvents
+}
+
+func (m mockClusterComponents) Auth(ctx context.Context, auth types.Object) handlers.TServerSSH {
+	host := handlers.NewNodeAuth(ctx, auth).Host.ValueString()
+	return mockKubeconfigGoodSSH{&mockSSH{hostname: host}}
+}
+
+func (m mockClusterComponents) Server(ctx context.Context, model *handlers.ServerClientModel) (handlers.TClusterServer, error) {
+	host := handlers.NewNodeAuth(ctx, model.Auth).Host.ValueString()
+	m.events.mu.Lock()
+	m.events.joined[host] = handlers.NewHaConfig(ctx, model.HaConfig).Server.ValueString()
+	m.events.mu.Unlock()
+	return mockClusterServer{events: m.events, host: host}, nil
+}
+
+func (m mockClusterComponents) Agent(ctx context.Context, model *handlers.AgentClientModel) (handlers.TClusterAgent, error) {
+	host := handlers.NewNodeAuth(ctx, model.Auth).Host.ValueString()
+	return mockClusterAgent{events: m.events, host: host}, nil
+}
+
+func (m mockClusterComponents) NodeRemoval(context.Context, string) (k3s.NodeRemoval, error) {
+	return mockNodeRemoval{m.events}, nil
+}
+
+func newClusterComponents(failOn string) mockClusterComponents {
+	return mockClusterComponents{&clusterEvents{failOn: failOn, joined: map[string]string{}}}
+}
+
+func clusterNode(t *testing.T, name string, host string, config string) handlers.ClusterNode {
+	auth := handlers.NodeAuth{
+		Host:       types.StringValue(host),
+		Port:       types.Int32Null(),
+		PrivateKey: types.StringNull(),
+		Password:   types.StringNull(),
+		User:       types.StringNull(),
+	}
+	k3sConfig := handlers.NewYamlNull()
+	if config != "" {
+		k3sConfig = handlers.NewYamlValue(config)
+	}
+	return handlers.ClusterNode{
+		Name:      types.StringValue(name),
+		Auth:      auth.ToObject(t.Context()),
+		K3sConfig: k3sConfig,
+	}
+}
+
+func clusterNodes(t *testing.T, nodes ...handlers.ClusterNode) types.List {
+	list, diags := types.ListValueFrom(t.Context(), types.ObjectType{AttrTypes: handlers.ClusterNode{}.AttributeTypes()}, nodes)
+	if diags.HasError() {
+		t.Fatalf("building node list: %v", diags)
+	}
+	return list
+}
+
+func nodeNames(t *testing.T, list types.List) (names []string) {
+	var nodes []handlers.ClusterNode
+	list.ElementsAs(t.Context(), &nodes, false)
+	for _, node := range nodes {
+		names = append(names, node.Name.ValueString())
+	}
+	return
+}
+
+func testCluster(t *testing.T) handlers.ClusterModel {
+	return handlers.ClusterModel{
+		Servers: clusterNodes(t,
+			clusterNode(t, "s1", "10.0.0.1", ""),
+			clusterNode(t, "s2", "10.0.0.2", ""),
+			clusterNode(t, "s3", "10.0.0.3", ""),
+		),
+		Agents: clusterNodes(t,
+			clusterNode(t, "a1", "10.0.1.1", ""),
+			clusterNode(t, "a2", "10.0.1.2", ""),
+		),
+		K3sRegistry: handlers.NewYamlNull(),
+	}
+}
+
+func TestClusterHandlerValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		servers     []handlers.ClusterNode
+		agents      []handlers.ClusterNode
+		parallelism types.Int64
+		fails       bool
+	}{
+		{"Good", []handlers.ClusterNode{clusterNode(t, "s1", "10.0.0.1", "")}, []handlers.ClusterNode{clusterNode(t, "a1", "10.0.1.1", "")}, types.Int64Value(2), false},
+		{"No servers", nil, []handlers.ClusterNode{clusterNode(t, "a1", "10.0.1.1", "")}, types.Int64Null(), true},
+		{"Duplicate name", []handlers.ClusterNode{clusterNode(t, "s1", "10.0.0.1", "")}, []handlers.ClusterNode{clusterNode(t, "s1", "10.0.1.1", "")}, types.Int64Null(), true},
+		{"Duplicate host", []handlers.ClusterNode{clusterNode(t, "s1", "10.0.0.1", ""), clusterNode(t, "s2", "10.0.0.1", "")}, nil, types.Int64Null(), true},
+		{"No parallelism", []handlers.ClusterNode{clusterNode(t, "s1", "10.0.0.1", "")}, nil, types.Int64Value(0), true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cluster := handlers.ClusterModel{
+				Servers:     clusterNodes(t, c.servers...),
+				Agents:      clusterNodes(t, c.agents...),
+				Parallelism: c.parallelism,
+			}
+			if err := cluster.Validate(t.Context()); (err != nil) != c.fails {
+				t.Errorf("expected failure %t, got %v", c.fails, err)
+			}
+		})
+	}
+}
+
+func TestClusterHandlerCreate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Good create", func(t *testing.T) {
+		components := newClusterComponents("")
+		cluster := testCluster(t)
+		if err := cluster.Create(t.Context(), components); err != nil {
+			t.Fatalf("Good create shouldn't raise, got %s", err.Error())
+		}
+
+		events := components.events
+		if events.index("install server 10.0.0.1") != 0 {
+			t.Errorf("init server should be installed first, got %v", events.events)
+		}
+		if events.index("install server 10.0.0.2") > events.index("install server 10.0.0.3") {
+			t.Errorf("servers should join in order, got %v", events.events)
+		}
+		for _, agent := range []string{"install agent 10.0.1.1", "install agent 10.0.1.2"} {
+			if events.index(agent) < events.index("install server 10.0.0.3") {
+				t.Errorf("agents should join after every server, got %v", events.events)
+			}
+		}
+		if events.joined["10.0.0.1"] != "" || events.joined["10.0.0.2"] != "https://10.0.0.1:6443" {
+			t.Errorf("servers should join the init server, got %v", events.joined)
+		}
+
+		if cluster.Id.ValueString() != "cluster,10.0.0.1" {
+			t.Errorf("unexpected id %s", cluster.Id.ValueString())
+		}
+		if cluster.Server.ValueString() != "https://10.0.0.1:6443" {
+			t.Errorf("unexpected server %s", cluster.Server.ValueString())
+		}
+		if cluster.Token.ValueString() != "token" || cluster.AgentToken.ValueString() != "agent-token" {
+			t.Errorf("tokens should come from the init server")
+		}
+		if !cluster.Active.ValueBool() {
+			t.Errorf("cluster should be active")
+		}
+	})
+
+	t.Run("Failed init server", func(t *testing.T) {
+		cluster := testCluster(t)
+		if err := cluster.Create(t.Context(), newClusterComponents("install server 10.0.0.1")); err == nil {
+			t.Fatalf("Failed init server should raise")
+		}
+		if !cluster.Id.IsNull() {
+			t.Errorf("Nothing was created, id should be null")
+		}
+	})
+
+	t.Run("Failed server join", func(t *testing.T) {
+		components := newClusterComponents("install server 10.0.0.2")
+		cluster := testCluster(t)
+		if err := cluster.Create(t.Context(), components); err == nil {
+			t.Fatalf("Failed server join should raise")
+		}
+		if names := nodeNames(t, cluster.Servers); !slices.Equal(names, []string{"s1"}) {
+			t.Errorf("only created servers should be kept, got %v", names)
+		}
+		if names := nodeNames(t, cluster.Agents); len(names) != 0 {
+			t.Errorf("no agent should be kept, got %v", names)
+		}
+		if components.events.index("install server 10.0.0.3") >= 0 {
+			t.Errorf("servers after the failure shouldn't be installed")
+		}
+	})
+
+	t.Run("Failed agent join", func(t *testing.T) {
+		cluster := testCluster(t)
+		err := cluster.Create(t.Context(), newClusterComponents("install agent 10.0.1.2"))
+		var nodeErr *k3s.NodeError
+		if !errors.As(err, &nodeErr) || nodeErr.Node != "a2" {
+			t.Fatalf("Failed agent join should raise for a2, got %v", err)
+		}
+		if names := nodeNames(t, cluster.Servers); len(names) != 3 {
+			t.Errorf("every server should be kept, got %v", names)
+		}
+		if names := nodeNames(t, cluster.Agents); !slices.Equal(names, []string{"a1"}) {
+			t.Errorf("only created agents should be kept, got %v", names)
+		}
+	})
+}
+
+func TestClusterHandlerUpdate(t *testing.T) {
+	t.Parallel()
+
+	created := func(t *testing.T) handlers.ClusterModel {
+		cluster := testCluster(t)
+		if err := cluster.Create(t.Context(), newClusterComponents("")); err != nil {
+			t.Fatalf("creating cluster: %s", err.Error())
+		}
+		return cluster
+	}
+
+	t.Run("Scale and update", func(t *testing.T) {
+		cluster := created(t)
+		inc := testCluster(t)
+		inc.Servers = clusterNodes(t,
+			clusterNode(t, "s1", "10.0.0.1", "node-label: [a=b]"),
+			clusterNode(t, "s2", "10.0.0.2", ""),
+		)
+		inc.Agents = clusterNodes(t,
+			clusterNode(t, "a1", "10.0.1.1", ""),
+			clusterNode(t, "a3", "10.0.1.3", ""),
+		)
+
+		components := newClusterComponents("")
+		if err := cluster.Update(t.Context(), inc, components); err != nil {
+			t.Fatalf("Update shouldn't raise, got %s", err.Error())
+		}
+
+		expected := []string{
+			"install agent 10.0.1.3",
+			"update server 10.0.0.1",
+			"ready server 10.0.0.1",
+			"drain 10.0.1.2",
+			"uninstall agent 10.0.1.2",
+			"drain 10.0.0.3",
+			"uninstall server 10.0.0.3",
+		}
+		if !slices.Equal(components.events.events, expected) {
+			t.Errorf("expected %v, got %v", expected, components.events.events)
+		}
+		if names := nodeNames(t, cluster.Agents); !slices.Equal(names, []string{"a1", "a3"}) {
+			t.Errorf("unexpected agents %v", names)
+		}
+		if cluster.Token.ValueString() != "token" {
+			t.Errorf("token should be kept")
+		}
+	})
+
+	t.Run("Moved node", func(t *testing.T) {
+		cluster := created(t)
+		inc := testCluster(t)
+		inc.Agents = clusterNodes(t,
+			clusterNode(t, "a1", "10.0.1.1", ""),
+			clusterNode(t, "a2", "10.0.1.9", ""),
+		)
+
+		components := newClusterComponents("")
+		if err := cluster.Update(t.Context(), inc, components); err != nil {
+			t.Fatalf("Update shouldn't raise, got %s", err.Error())
+		}
+		expected := []string{"install agent 10.0.1.9", "drain 10.0.1.2", "uninstall agent 10.0.1.2"}
+		if !slices.Equal(components.events.events, expected) {
+			t.Errorf("expected %v, got %v", expected, components.events.events)
+		}
+	})
+
+	t.Run("Rolling update", func(t *testing.T) {
+		cluster := created(t)
+		inc := testCluster(t)
+		inc.K3sRegistry = handlers.NewYamlValue("mirrors: {}")
+
+		components := newClusterComponents("")
+		if err := cluster.Update(t.Context(), inc, components); err != nil {
+			t.Fatalf("Update shouldn't raise, got %s", err.Error())
+		}
+		expected := []string{
+			"update server 10.0.0.1", "ready server 10.0.0.1",
+			"update server 10.0.0.2", "ready server 10.0.0.2",
+			"update server 10.0.0.3", "ready server 10.0.0.3",
+			"update agent 10.0.1.1", "ready agent 10.0.1.1",
+			"update agent 10.0.1.2", "ready agent 10.0.1.2",
+		}
+		if !slices.Equal(components.events.events, expected) {
+			t.Errorf("expected %v, got %v", expected, components.events.events)
+		}
+	})
+
+	t.Run("Unhealthy node halts", func(t *testing.T) {
+		cluster := created(t)
+		inc := testCluster(t)
+		inc.K3sRegistry = handlers.NewYamlValue("mirrors: {}")
+
+		components := newClusterComponents("ready server 10.0.0.2")
+		err := cluster.Update(t.Context(), inc, components)
+		if err == nil {
+			t.Fatalf("Unhealthy node should raise")
+		}
+		if !strings.Contains(err.Error(), "halted with s3 untouched") {
+			t.Errorf("error should name the untouched nodes, got %s", err.Error())
+		}
+		for _, event := range []string{"update server 10.0.0.3", "update agent 10.0.1.1"} {
+			if components.events.index(event) >= 0 {
+				t.Errorf("nodes after the unhealthy one shouldn't be touched, got %v", components.events.events)
+			}
+		}
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		cluster := created(t)
+		inc := testCluster(t)
+		inc.UpdateBatchSize = types.Int64Value(2)
+		inc.Agents = clusterNodes(t,
+			clusterNode(t, "a1", "10.0.1.1", "node-label: [a=b]"),
+			clusterNode(t, "a2", "10.0.1.2", "node-label: [a=b]"),
+		)
+
+		components := newClusterComponents("ready agent 10.0.1.1")
+		if err := cluster.Update(t.Context(), inc, components); err == nil {
+			t.Fatalf("Unhealthy agent should raise")
+		}
+		if components.events.index("update agent 10.0.1.2") < 0 {
+			t.Errorf("agents of the same batch should both update, got %v", components.events.events)
+		}
+	})
+
+	t.Run("Changed init server", func(t *testing.T) {
+		cluster := created(t)
+		inc := testCluster(t)
+		inc.Servers = clusterNodes(t, clusterNode(t, "s2", "10.0.0.2", ""))
+		if err := cluster.Update(t.Context(), inc, newClusterComponents("")); err == nil {
+			t.Errorf("Changing the init server should raise")
+		}
+	})
+
+	t.Run("Failed drain", func(t *testing.T) {
+		cluster := created(t)
+		inc := testCluster(t)
+		inc.Agents = clusterNodes(t, clusterNode(t, "a1", "10.0.1.1", ""))
+
+		components := newClusterComponents("drain 10.0.1.2")
+		if err := cluster.Update(t.Context(), inc, components); err == nil {
+			t.Fatalf("Failed drain should raise")
+		}
+		if components.events.index("uninstall agent 10.0.1.2") >= 0 {
+			t.Errorf("node that wasn't drained shouldn't be uninstalled")
+		}
+	})
+
+	t.Run("Partial failure", func(t *testing.T) {
+		cluster := created(t)
+		inc := testCluster(t)
+		inc.Servers = clusterNodes(t,
+			clusterNode(t, "s1", "10.0.0.1", "node-label: [a=b]"),
+			clusterNode(t, "s2", "10.0.0.2", ""),
+		)
+		inc.Agents = clusterNodes(t,
+			clusterNode(t, "a1", "10.0.1.1", ""),
+			clusterNode(t, "a3", "10.0.1.3", ""),
+		)
+
+		components := newClusterComponents("drain 10.0.0.3")
+		if err := cluster.Update(t.Context(), inc, components); err == nil {
+			t.Fatalf("Failed drain should raise")
+		}
+		if names := nodeNames(t, cluster.Agents); !slices.Equal(names, []string{"a1", "a3"}) {
+			t.Errorf("agents should be the ones installed, got %v", names)
+		}
+		if names := nodeNames(t, cluster.Servers); !slices.Equal(names, []string{"s1", "s2", "s3"}) {
+			t.Errorf("server that wasn't removed should be kept, got %v", names)
+		}
+		var servers []handlers.ClusterNode
+		cluster.Servers.ElementsAs(t.Context(), &servers, false)
+		if servers[0].K3sConfig.ValueString() != "node-label: [a=b]" {
+			t.Errorf("updated server should hold its new config, got %q", servers[0].K3sConfig.ValueString())
+		}
+		if cluster.Active.ValueBool() {
+			t.Errorf("cluster shouldn't be active after a failed update")
+		}
+	})
+}
+
+func TestClusterHandlerRead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Healthy", func(t *testing.T) {
+		cluster := testCluster(t)
+		if err := cluster.Read(t.Context(), newClusterComponents("")); err != nil {
+			t.Fatalf("Read shouldn't raise, got %s", err.Error())
+		}
+		if !cluster.Active.ValueBool() {
+			t.Errorf("cluster should be active")
+		}
+	})
+
+	t.Run("Unreachable node", func(t *testing.T) {
+		cluster := testCluster(t)
+		if err := cluster.Read(t.Context(), newClusterComponents("resync agent 10.0.1.2")); err != nil {
+			t.Fatalf("Unreachable node shouldn't raise, got %s", err.Error())
+		}
+		if cluster.Active.ValueBool() {
+			t.Errorf("cluster with an unreachable node shouldn't be active")
+		}
+	})
+}
+
+func TestClusterHandlerDelete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Order", func(t *testing.T) {
+		components := newClusterComponents("")
+		cluster := testCluster(t)
+		if err := cluster.Delete(t.Context(), components); err != nil {
+			t.Fatalf("Delete shouldn't raise, got %s", err.Error())
+		}
+		servers := components.events.events[2:]
+		expected := []string{"uninstall server 10.0.0.3", "uninstall server 10.0.0.2", "uninstall server 10.0.0.1"}
+		if !slices.Equal(servers, expected) {
+			t.Errorf("servers should be uninstalled after agents in reverse, got %v", components.events.events)
+		}
+	})
+
+	t.Run("Failed node", func(t *testing.T) {
+		components := newClusterComponents("uninstall agent 10.0.1.1")
+		cluster := testCluster(t)
+		if err := cluster.Delete(t.Context(), components); err == nil {
+			t.Fatalf("Failed uninstall should raise")
+		}
+		if components.events.index("uninstall server 10.0.0.1") < 0 {
+			t.Errorf("every node should still be uninstalled, got %v", components.events.events)
+		}
+	})
+}
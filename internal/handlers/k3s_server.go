@@ -4,32 +4,49 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"striveworks.us/terraform-provider-k3s/internal/k3s"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
 )
 
 type ServerClientModel struct {
 	Auth types.Object `tfsdk:"auth"`
+	// Write-only credentials, never stored in state
+	AuthWo        types.Object `tfsdk:"auth_wo"`
+	AuthWoVersion types.Int64  `tfsdk:"auth_wo_version"`
 	// Configs
 	BinDir      types.String `tfsdk:"bin_dir"`
-	K3sConfig   types.String `tfsdk:"config"`
-	K3sRegistry types.String `tfsdk:"registry"`
+	K3sConfig   YamlValue    `tfsdk:"config"`
+	K3sRegistry YamlValue    `tfsdk:"registry"`
+	// Typed config, merged on top of config
+	ServerConfig types.Object `tfsdk:"server_config"`
 	// Highly Available config
 	HaConfig types.Object `tfsdk:"highly_available"`
 	// OIDC Support
 	OidcConfig types.Object `tfsdk:"oidc"`
+	// External datastore
+	DatastoreConfig types.Object `tfsdk:"datastore"`
 	// Outputs
-	Id          types.String `tfsdk:"id"`
-	Server      types.String `tfsdk:"server"`
-	KubeConfig  types.String `tfsdk:"kubeconfig"`
-	Token       types.String `tfsdk:"token"`
-	Active      types.Bool   `tfsdk:"active"`
-	ClusterAuth types.Object `tfsdk:"cluster_auth"`
-
-	version    string
-	haConfig   *HaConfig
-	oidcConfig *OidcConfig
+	Id                types.String `tfsdk:"id"`
+	Server            types.String `tfsdk:"server"`
+	KubeConfig        types.String `tfsdk:"kubeconfig"`
+	Token             types.String `tfsdk:"token"`
+	AgentToken        types.String `tfsdk:"agent_token"`
+	Active            types.Bool   `tfsdk:"active"`
+	ClusterAuth       types.Object `tfsdk:"cluster_auth"`
+	CertificateExpiry types.Map    `tfsdk:"certificate_expiry"`
+	// Wait for the node to be Ready after install
+	WaitForReady types.Object `tfsdk:"wait_for_ready"`
+	// Deadlines of each operation
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+
+	version         string
+	warnings        []string
+	haConfig        *HaConfig
+	oidcConfig      *OidcConfig
+	datastoreConfig *DatastoreConfig
 }
 
 func (s *ServerClientModel) SetVersion(version *string) {
@@ -38,10 +55,39 @@ func (s *ServerClientModel) SetVersion(version *string) {
 	}
 }
 
+// Problems which didn't fail the last operation, to report as warnings.
+func (s *ServerClientModel) Warnings() []string {
+	return s.warnings
+}
+
+// The expiry is informational, a node that can't report it still works so
+// it's left null with a warning.
+func (s *ServerClientModel) setCertificateExpiry(ctx context.Context, server k3s.ServerCertificates, sshClient ssh_client.SSHRun) {
+	certificateExpiry, err := server.CertificateExpiry(sshClient)
+	if err != nil {
+		warning := fmt.Sprintf("fetching certificate expiry: %s", err.Error())
+		tflog.Warn(ctx, warning)
+		s.warnings = append(s.warnings, warning)
+		s.CertificateExpiry = types.MapNull(types.StringType)
+		return
+	}
+	tflog.Debug(ctx, "k3s server certificate expiry fetched")
+	s.CertificateExpiry, _ = types.MapValueFrom(ctx, types.StringType, certificateExpiry)
+}
+
 func (s *ServerClientModel) ToServer(ctx context.Context) (k3s.Server, error) {
+	config := s.K3sConfig.ValueString()
+	if !s.ServerConfig.IsNull() && !s.ServerConfig.IsUnknown() {
+		merged, err := mergeConfig(s.K3sConfig, NewServerConfig(ctx, s.ServerConfig).config(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("merging server_config: %s", err.Error())
+		}
+		config = merged
+	}
+
 	server, err := k3s.NewK3sServerComponent(
 		ctx,
-		s.K3sConfig.ValueString(),
+		config,
 		s.K3sRegistry.ValueString(),
 		s.version,
 		s.BinDir.ValueString(),
@@ -63,9 +109,30 @@ func (s *ServerClientModel) ToServer(ctx context.Context) (k3s.Server, error) {
 		s.oidcConfig.configureServer(server)
 	}
 
+	if !s.DatastoreConfig.IsNull() {
+		cfg := NewDatastoreConfig(ctx, s.DatastoreConfig)
+		s.datastoreConfig = &cfg
+		s.datastoreConfig.configureServer(server)
+	}
+
 	return server, nil
 }
 
+// Write-only values are never part of a plan, they are taken from the config.
+func (s *ServerClientModel) SetWriteOnly(ctx context.Context, config ServerClientModel) {
+	s.AuthWo = config.AuthWo
+	if !s.HaConfig.IsNull() && !s.HaConfig.IsUnknown() {
+		cfg := NewHaConfig(ctx, s.HaConfig)
+		cfg.TokenWo = NewHaConfig(ctx, config.HaConfig).TokenWo
+		s.HaConfig = cfg.ToObject(ctx)
+	}
+	if !s.OidcConfig.IsNull() && !s.OidcConfig.IsUnknown() {
+		cfg := NewOidcConfig(ctx, s.OidcConfig)
+		cfg.SigningKeyWo = NewOidcConfig(ctx, config.OidcConfig).SigningKeyWo
+		s.OidcConfig = cfg.ToObject(ctx)
+	}
+}
+
 type TServerSSH interface {
 	K3sTypeSSH
 	K3sTypeToObject
@@ -75,8 +142,12 @@ type TK3sServerRead interface {
 	k3s.ComponentResync
 	k3s.ComponentStatus
 	k3s.ComponentToken
+	k3s.ServerAgentToken
+	k3s.ServerCertificates
+	k3s.ServerConfig
 	k3s.ServerKubeconfig
 	k3s.ServerOidc
+	k3s.ServerRegistry
 }
 
 func (s *ServerClientModel) Read(
@@ -90,11 +161,22 @@ func (s *ServerClientModel) Read(
 	}
 	tflog.Debug(ctx, "k3s server ssh client created")
 
+	// What the provider manages, before being replaced with the remote files
+	config, registry := server.Config(), server.Registry()
+
 	if err := server.Resync(sshClient); err != nil {
 		return fmt.Errorf("error resyncing server: %s", err.Error())
 	}
 	tflog.Debug(ctx, "k3s server resynced")
 
+	if s.K3sConfig, err = driftedYaml(s.K3sConfig, config, server.Config()); err != nil {
+		return fmt.Errorf("comparing remote config: %s", err.Error())
+	}
+	if s.K3sRegistry, err = driftedYaml(s.K3sRegistry, registry, server.Registry()); err != nil {
+		return fmt.Errorf("comparing remote registry: %s", err.Error())
+	}
+	tflog.Debug(ctx, "k3s server config and registry compared")
+
 	status, err := server.Status(sshClient)
 	if err != nil {
 		return fmt.Errorf("fetching status or status logs: %s", err.Error())
@@ -105,6 +187,8 @@ func (s *ServerClientModel) Read(
 		return fmt.Errorf("fetching cluster auth: %s", err.Error())
 	}
 
+	s.setCertificateExpiry(ctx, server, sshClient)
+
 	if s.oidcConfig != nil {
 		if err := s.oidcConfig.setJwks(sshClient); err != nil {
 			return err
@@ -120,10 +204,112 @@ func (s *ServerClientModel) Read(
 	s.Active = types.BoolValue(status)
 	s.KubeConfig = types.StringValue(server.KubeConfig())
 	s.Token = types.StringValue(server.Token())
+	s.AgentToken = types.StringValue(server.AgentToken())
+
+	return nil
+}
+
+// Adopts an existing k3s server, populating the model from the remote node.
+func (s *ServerClientModel) Import(
+	ctx context.Context,
+	auth TServerSSH,
+	server TK3sServerRead,
+) error {
+	s.AuthWo = types.ObjectNull(AuthWriteOnly{}.AttributeTypes())
+	s.ServerConfig = types.ObjectNull(ServerConfig{}.AttributeTypes())
+	s.WaitForReady = types.ObjectNull(WaitForReady{}.AttributeTypes())
+	s.Timeouts = DefaultTimeouts()
+	s.HaConfig = types.ObjectNull(HaConfig{}.AttributeTypes())
+	s.OidcConfig = types.ObjectNull(OidcConfig{}.AttributeTypes())
+	s.DatastoreConfig = types.ObjectNull(DatastoreConfig{}.AttributeTypes())
+	if s.BinDir.IsNull() {
+		s.BinDir = types.StringValue(k3s.BIN_DIR)
+	}
+
+	// Nothing is managed yet, so Read takes the remote config and registry as they are
+	if err := s.Read(ctx, auth, server); err != nil {
+		return err
+	}
+	if err := s.importSecrets(ctx); err != nil {
+		return err
+	}
+
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+
+	clusterAuth, err := BuildClusterAuth(server.KubeConfig())
+	if err != nil {
+		return fmt.Errorf("fetching cluster auth: %s", err.Error())
+	}
+	if err := clusterAuth.UpdateHost(sshClient.HostnameOrIpAddress()); err != nil {
+		return fmt.Errorf("updating kubeconfig host: %s", err.Error())
+	}
+
+	s.ClusterAuth = clusterAuth.ToObject(ctx)
+	s.KubeConfig = types.StringValue(clusterAuth.KubeConfig())
+	s.Id = types.StringValue(fmt.Sprintf("server,%s", sshClient.HostnameOrIpAddress()))
+	s.Server = clusterAuth.Server
 
 	return nil
 }
 
+// Moves the secrets the provider injects into the config out of the imported
+// config and into their sensitive attributes, so they never land in the plain
+// `config`. A token with no attribute to go to is still in the `token` output.
+func (s *ServerClientModel) importSecrets(ctx context.Context) error {
+	config, err := k3s.ParseYamlString(s.K3sConfig.StringValue)
+	if err != nil {
+		return fmt.Errorf("parsing remote config: %s", err.Error())
+	}
+	take := func(key string) types.String {
+		value, ok := config[key]
+		if !ok {
+			return types.StringNull()
+		}
+		delete(config, key)
+		return types.StringValue(fmt.Sprint(value))
+	}
+
+	token, agentToken := take("token"), take("agent-token")
+	if endpoint := take("datastore-endpoint"); !endpoint.IsNull() {
+		datastore := DatastoreConfig{
+			Endpoint: endpoint,
+			CA:       types.StringNull(),
+			Cert:     types.StringNull(),
+			Key:      types.StringNull(),
+			Username: types.StringNull(),
+			Password: types.StringNull(),
+			Token:    token,
+		}
+		s.DatastoreConfig = datastore.ToObject(ctx)
+		tflog.Debug(ctx, "imported datastore endpoint")
+	} else {
+		ha := HaConfig{
+			ClusterInit:    types.BoolValue(false),
+			Token:          types.StringNull(),
+			AgentToken:     agentToken,
+			Server:         types.StringNull(),
+			TokenWo:        types.StringNull(),
+			TokenWoVersion: types.Int64Null(),
+		}
+		switch {
+		case config["cluster-init"] == true:
+			delete(config, "cluster-init")
+			ha.ClusterInit = types.BoolValue(true)
+			s.HaConfig = ha.ToObject(ctx)
+		case !token.IsNull() && config["server"] != nil:
+			ha.Token, ha.Server = token, take("server")
+			s.HaConfig = ha.ToObject(ctx)
+		}
+		tflog.Debug(ctx, "imported high availability config")
+	}
+
+	s.K3sConfig, err = yamlString(config)
+	return err
+}
+
 type TK3sServerDelete interface {
 	k3s.ComponentUninstall
 }
@@ -155,7 +341,10 @@ type TServerCreate interface {
 	k3s.ComponentStatus
 	k3s.ServerKubeconfig
 	k3s.ComponentToken
+	k3s.ServerAgentToken
+	k3s.ServerCertificates
 	k3s.ServerOidc
+	k3s.ComponentWaitForReady
 }
 
 func (s *ServerClientModel) Create(
@@ -189,7 +378,18 @@ func (s *ServerClientModel) Create(
 	if err != nil {
 		return fmt.Errorf("fetching status kubeconfig: %s", err.Error())
 	}
-	clusterAuth.UpdateHost(sshClient.HostnameOrIpAddress())
+	if err := clusterAuth.UpdateHost(sshClient.HostnameOrIpAddress()); err != nil {
+		return fmt.Errorf("updating kubeconfig host: %s", err.Error())
+	}
+
+	if !s.WaitForReady.IsNull() {
+		if err := server.WaitForReady(sshClient, clusterAuth.KubeConfig(), NewWaitForReady(ctx, s.WaitForReady).timeout()); err != nil {
+			return fmt.Errorf("waiting for k3s server to be ready: %s", err.Error())
+		}
+		tflog.Debug(ctx, "k3s server ready")
+	}
+
+	s.setCertificateExpiry(ctx, server, sshClient)
 
 	if s.oidcConfig != nil {
 		if err := s.oidcConfig.setJwks(sshClient); err != nil {
@@ -205,6 +405,7 @@ func (s *ServerClientModel) Create(
 	s.ClusterAuth = clusterAuth.ToObject(ctx)
 	s.KubeConfig = types.StringValue(clusterAuth.KubeConfig())
 	s.Token = types.StringValue(server.Token())
+	s.AgentToken = types.StringValue(server.AgentToken())
 	s.Id = types.StringValue(fmt.Sprintf("server,%s", sshClient.HostnameOrIpAddress()))
 	s.Server = clusterAuth.Server
 	s.Active = types.BoolValue(status)
@@ -233,8 +434,12 @@ func (s *ServerClientModel) Update(
 	}
 	tflog.Debug(ctx, "k3s server ssh client created")
 
-	if s.K3sConfig.Equal(inc.K3sConfig) && s.K3sRegistry.Equal(inc.K3sRegistry) && s.OidcConfig.Equal(inc.OidcConfig) {
-		tflog.Debug(ctx, "No change is needed, only supporting config, registry and oidc updates")
+	if s.K3sConfig.semanticEqual(inc.K3sConfig) &&
+		s.K3sRegistry.semanticEqual(inc.K3sRegistry) &&
+		s.ServerConfig.Equal(inc.ServerConfig) &&
+		!oidcChanged(ctx, s.OidcConfig, inc.OidcConfig) &&
+		s.DatastoreConfig.Equal(inc.DatastoreConfig) {
+		tflog.Debug(ctx, "No change is needed, only supporting config, registry, oidc and datastore updates")
 		return nil
 	}
 
@@ -254,12 +459,13 @@ func (s *ServerClientModel) Update(
 	}
 	tflog.Debug(ctx, "k3s server status ran")
 
-	if s.oidcConfig != nil {
-		if err := s.oidcConfig.setJwks(sshClient); err != nil {
+	s.OidcConfig = inc.OidcConfig
+	if inc.oidcConfig != nil {
+		if err := inc.oidcConfig.setJwks(sshClient); err != nil {
 			return err
 		}
 		tflog.Debug(ctx, "k3s server fetched jwks")
-		s.OidcConfig = s.oidcConfig.ToObject(ctx)
+		s.OidcConfig = inc.oidcConfig.ToObject(ctx)
 	}
 
 	if s.haConfig != nil {
@@ -269,6 +475,8 @@ func (s *ServerClientModel) Update(
 	s.Active = types.BoolValue(status)
 	s.K3sRegistry = inc.K3sRegistry
 	s.K3sConfig = inc.K3sConfig
+	s.ServerConfig = inc.ServerConfig
+	s.DatastoreConfig = inc.DatastoreConfig
 
 	return nil
 }
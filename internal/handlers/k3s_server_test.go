@@ -2,8 +2,11 @@ package handlers_test
 
 import (
 	"fmt"
+	"maps"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"striveworks.us/terraform-provider-k3s/internal/handlers"
 	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
@@ -16,6 +19,12 @@ type mockServer struct {
 	uninstall       error
 	preinstallError error
 	installError    error
+	notReady        error
+	expiryError     error
+	// Managed config, replaced by the remote config on resync
+	config       map[any]any
+	remoteConfig map[any]any
+	resynced     bool
 }
 
 // Install implements handlers.TServerCreate.
@@ -42,12 +51,33 @@ func (m *mockServer) KubeConfig() string {
 	return TestMockKubeconfig
 }
 
-func (m mockServer) Resync(ssh_client.SSHClient) error { return m.resync }
+func (m *mockServer) Resync(ssh_client.SSHClient) error {
+	m.resynced = true
+	return m.resync
+}
 func (m mockServer) Status(ssh_client.SSHClient) (bool, error) {
 	return m.status, m.statusErr
 }
-func (mockServer) Server() string { return "" }
-func (mockServer) Token() string  { return "" }
+func (mockServer) Server() string     { return "" }
+func (mockServer) Token() string      { return "" }
+func (mockServer) AgentToken() string { return "" }
+func (m mockServer) CertificateExpiry(ssh_client.SSHRun) (map[string]string, error) {
+	if m.expiryError != nil {
+		return nil, m.expiryError
+	}
+	return map[string]string{"client-admin.crt": "2026-05-25T15:05:44Z"}, nil
+}
+func (mockServer) RotateCertificates(ssh_client.SSHClient, []string) error { return nil }
+func (m mockServer) WaitForReady(ssh_client.SSHClient, string, time.Duration) error {
+	return m.notReady
+}
+func (m mockServer) Config() map[any]any {
+	if m.resynced {
+		return m.remoteConfig
+	}
+	return m.config
+}
+func (mockServer) Registry() map[any]any { return nil }
 func (m mockServer) Uninstall(ssh_client.SSHClient, string, ...bool) error {
 	return m.uninstall
 }
@@ -169,4 +199,184 @@ func TestServerHandlerCreate(t *testing.T) {
 		}
 	})
 
+	t.Run("Not ready", func(t *testing.T) {
+		data := handlers.ServerClientModel{
+			WaitForReady: (&handlers.WaitForReady{Timeout: types.StringValue("1m")}).ToObject(t.Context()),
+		}
+		ssh := mockSSH{}
+		err := data.Create(t.Context(), &mockKubeconfigGoodSSH{&ssh}, &mockServer{notReady: fmt.Errorf("node is not Ready")})
+		if err == nil {
+			t.Errorf("Node that isn't ready should raise")
+		}
+	})
+
+	t.Run("Not ready without waiting", func(t *testing.T) {
+		var data handlers.ServerClientModel
+		ssh := mockSSH{}
+		err := data.Create(t.Context(), &mockKubeconfigGoodSSH{&ssh}, &mockServer{notReady: fmt.Errorf("node is not Ready")})
+		if err != nil {
+			t.Errorf("Readiness shouldn't be checked without wait_for_ready, got %s", err.Error())
+		}
+	})
+
+	t.Run("Certificate expiry unavailable", func(t *testing.T) {
+		var data handlers.ServerClientModel
+		ssh := mockSSH{}
+		err := data.Create(t.Context(), &mockKubeconfigGoodSSH{&ssh}, &mockServer{expiryError: fmt.Errorf("no certificates")})
+		if err != nil {
+			t.Fatalf("Certificate expiry shouldn't fail an installed server, got %s", err.Error())
+		}
+		if !data.CertificateExpiry.IsNull() {
+			t.Errorf("Expected certificate expiry to be null, got %v", data.CertificateExpiry)
+		}
+		if len(data.Warnings()) != 1 {
+			t.Errorf("Expected a certificate expiry warning, got %v", data.Warnings())
+		}
+	})
+
+}
+
+func TestServerHandlerReadDrift(t *testing.T) {
+	t.Parallel()
+
+	t.Run("No drift", func(t *testing.T) {
+		data := handlers.ServerClientModel{K3sConfig: handlers.NewYamlValue("a:   1\nb:\n  - x\n")}
+		err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockServer{
+			// token is injected by the provider
+			config:       map[any]any{"a": 1, "b": []any{"x"}, "token": "abc"},
+			remoteConfig: map[any]any{"b": []any{"x"}, "token": "abc", "a": 1},
+		})
+		if err != nil {
+			t.Fatalf("Good read shouldn't raise, got %s", err.Error())
+		}
+		if !data.K3sConfig.Equal(handlers.NewYamlValue("a:   1\nb:\n  - x\n")) {
+			t.Errorf("Expected config to be untouched, got %s", data.K3sConfig)
+		}
+	})
+
+	t.Run("Drift", func(t *testing.T) {
+		data := handlers.ServerClientModel{K3sConfig: handlers.NewYamlValue("a: 1\n")}
+		err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockServer{
+			config:       map[any]any{"a": 1, "token": "abc"},
+			remoteConfig: map[any]any{"a": 2, "token": "def"},
+		})
+		if err != nil {
+			t.Fatalf("Good read shouldn't raise, got %s", err.Error())
+		}
+		if !data.K3sConfig.Equal(handlers.NewYamlValue("a: 2\n")) {
+			t.Errorf("Expected remote config without injected keys, got %s", data.K3sConfig)
+		}
+	})
+}
+
+func TestServerHandlerImport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Bad resync", func(t *testing.T) {
+		var data handlers.ServerClientModel
+		err := data.Import(t.Context(), &mockKubeconfigGoodSSH{}, &mockServer{resync: fmt.Errorf("error")})
+		if err == nil {
+			t.Errorf("Bad resync should raise, got nil")
+		}
+	})
+
+	t.Run("Good import", func(t *testing.T) {
+		var data handlers.ServerClientModel
+		ssh := mockSSH{hostname: "192.168.1.2"}
+		err := data.Import(t.Context(), &mockKubeconfigGoodSSH{&ssh}, &mockServer{
+			status:       true,
+			remoteConfig: map[any]any{"write-kubeconfig-mode": "0644"},
+		})
+		if err != nil {
+			t.Fatalf("Good import shouldn't raise, got %s", err.Error())
+		}
+		if !data.K3sConfig.Equal(handlers.NewYamlValue("write-kubeconfig-mode: \"0644\"\n")) {
+			t.Errorf("Expected config to be imported, got %s", data.K3sConfig)
+		}
+		if !data.K3sRegistry.IsNull() {
+			t.Errorf("Expected empty registry to be null, got %s", data.K3sRegistry)
+		}
+		if !data.Server.Equal(types.StringValue("https://192.168.1.2:6443")) {
+			t.Errorf("Expected server to use the node's host, got %s", data.Server)
+		}
+		if !data.Id.Equal(types.StringValue("server,192.168.1.2")) {
+			t.Errorf("Expected id server,192.168.1.2, got %s", data.Id)
+		}
+		if !data.Timeouts.IsNull() {
+			t.Errorf("Expected imported timeouts to be unset, got %s", data.Timeouts)
+		}
+	})
+
+	t.Run("Secrets", func(t *testing.T) {
+		for _, tc := range []struct {
+			name      string
+			remote    map[any]any
+			ha        map[string]attr.Value
+			datastore map[string]attr.Value
+		}{
+			{
+				name: "Joining server",
+				remote: map[any]any{
+					"server":      "https://10.0.0.1:6443",
+					"token":       "K10abc::server:secret",
+					"agent-token": "agent-secret",
+				},
+				ha: map[string]attr.Value{
+					"token":       types.StringValue("K10abc::server:secret"),
+					"agent_token": types.StringValue("agent-secret"),
+					"server":      types.StringValue("https://10.0.0.1:6443"),
+				},
+			},
+			{
+				name:   "Cluster init",
+				remote: map[any]any{"cluster-init": true, "token": "K10abc::server:secret"},
+				ha: map[string]attr.Value{
+					"cluster_init": types.BoolValue(true),
+					"token":        types.StringNull(),
+				},
+			},
+			{
+				name: "Datastore",
+				remote: map[any]any{
+					"datastore-endpoint": "postgres://k3s:hunter2@db:5432/k3s",
+					"token":              "datastore-secret",
+				},
+				datastore: map[string]attr.Value{
+					"endpoint": types.StringValue("postgres://k3s:hunter2@db:5432/k3s"),
+					"token":    types.StringValue("datastore-secret"),
+				},
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				var data handlers.ServerClientModel
+				ssh := mockSSH{hostname: "192.168.1.2"}
+				remote := map[any]any{"write-kubeconfig-mode": "0644"}
+				maps.Copy(remote, tc.remote)
+				err := data.Import(t.Context(), &mockKubeconfigGoodSSH{&ssh}, &mockServer{status: true, remoteConfig: remote})
+				if err != nil {
+					t.Fatalf("Good import shouldn't raise, got %s", err.Error())
+				}
+				if !data.K3sConfig.Equal(handlers.NewYamlValue("write-kubeconfig-mode: \"0644\"\n")) {
+					t.Errorf("Expected secrets to be left out of config, got %s", data.K3sConfig)
+				}
+				expectObject(t, "highly_available", tc.ha, data.HaConfig)
+				expectObject(t, "datastore", tc.datastore, data.DatastoreConfig)
+			})
+		}
+	})
+}
+
+// Checks the attributes of an object, which is expected null when there are none.
+func expectObject(t *testing.T, name string, expected map[string]attr.Value, obj types.Object) {
+	if expected == nil {
+		if !obj.IsNull() {
+			t.Errorf("Expected no %s, got %s", name, obj)
+		}
+		return
+	}
+	for key, value := range expected {
+		if !obj.Attributes()[key].Equal(value) {
+			t.Errorf("Expected %s.%s to be %s, got %s", name, key, value, obj.Attributes()[key])
+		}
+	}
 }
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+type TokenClientModel struct {
+	Auth types.Object `tfsdk:"auth"`
+	// Bootstrap token
+	Ttl         types.String `tfsdk:"ttl"`
+	Description types.String `tfsdk:"description"`
+	// Server token rotation
+	Rotate types.Object `tfsdk:"rotate"`
+	// Outputs
+	Id    types.String `tfsdk:"id"`
+	Token types.String `tfsdk:"token"`
+}
+
+type TTokenSSH interface {
+	K3sTypeSSH
+	// Same credentials against another node
+	ForHost(host string) K3sTypeSSH
+}
+
+func (t *TokenClientModel) Create(
+	ctx context.Context,
+	auth TTokenSSH,
+	server k3s.ServerTokens,
+) error {
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+	tflog.Debug(ctx, "k3s token ssh client created")
+
+	if t.Rotate.IsNull() {
+		token, err := server.CreateToken(sshClient, t.Ttl.ValueString(), t.Description.ValueString())
+		if err != nil {
+			return err
+		}
+		tflog.Debug(ctx, "k3s bootstrap token created")
+
+		t.Id = types.StringValue(k3s.TokenId(token))
+		t.Token = types.StringValue(token)
+		return nil
+	}
+
+	rotation := NewTokenRotation(ctx, t.Rotate)
+	if err := rotation.rotate(ctx, sshClient, auth, server); err != nil {
+		return err
+	}
+
+	t.Rotate = rotation.ToObject(ctx)
+	t.Id = types.StringValue(fmt.Sprintf("rotate,%s", sshClient.HostnameOrIpAddress()))
+	t.Token = rotation.NewToken
+	return nil
+}
+
+// Returns false when the bootstrap token no longer exists, either because it
+// expired or was deleted out of band.
+func (t *TokenClientModel) Read(
+	ctx context.Context,
+	auth K3sTypeSSH,
+	server k3s.ServerTokens,
+) (bool, error) {
+	if !t.Rotate.IsNull() {
+		tflog.Debug(ctx, "Nothing to read for a server token rotation")
+		return true, nil
+	}
+
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return false, fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+	tflog.Debug(ctx, "k3s token ssh client created")
+
+	ids, err := server.ListTokens(sshClient)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(ids, t.Id.ValueString()), nil
+}
+
+func (t *TokenClientModel) Update(
+	ctx context.Context,
+	inc TokenClientModel,
+	auth TTokenSSH,
+	server k3s.ServerTokens,
+) error {
+	if t.Rotate.Equal(inc.Rotate) {
+		tflog.Debug(ctx, "No change is needed, only supporting rotation updates")
+		return nil
+	}
+
+	existing := NewTokenRotation(ctx, t.Rotate)
+	rotation := NewTokenRotation(ctx, inc.Rotate)
+
+	if rotation.NewToken.IsNull() || rotation.NewToken.IsUnknown() || existing.NewToken.Equal(rotation.NewToken) {
+		// Same token, only bring newly tracked servers up to date
+		rotation.NewToken = existing.NewToken
+		if err := rotation.updateServers(ctx, existing.addedServers(ctx, rotation), auth, server); err != nil {
+			return err
+		}
+	} else {
+		sshClient, err := auth.SshClient(ctx)
+		if err != nil {
+			return fmt.Errorf("creating ssh config: %s", err.Error())
+		}
+		tflog.Debug(ctx, "k3s token ssh client created")
+
+		if err := rotation.rotate(ctx, sshClient, auth, server); err != nil {
+			return err
+		}
+	}
+
+	t.Rotate = rotation.ToObject(ctx)
+	t.Token = rotation.NewToken
+	return nil
+}
+
+func (t *TokenClientModel) Delete(
+	ctx context.Context,
+	auth K3sTypeSSH,
+	server k3s.ServerTokens,
+) error {
+	if !t.Rotate.IsNull() {
+		tflog.Info(ctx, "Server token rotations cannot be undone, removing from state only")
+		return nil
+	}
+
+	sshClient, err := auth.SshClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating ssh config: %s", err.Error())
+	}
+	tflog.Debug(ctx, "k3s token ssh client created")
+
+	if err := server.DeleteToken(sshClient, t.Id.ValueString()); err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "k3s bootstrap token deleted")
+
+	return nil
+}
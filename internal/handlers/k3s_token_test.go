@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+type mockTokens struct {
+	token     string
+	createErr error
+	ids       []string
+	rotateErr error
+
+	rotatedTo string
+	updated   []string
+	deleted   []string
+}
+
+func (m *mockTokens) CreateToken(ssh_client.SSHRun, string, string) (string, error) {
+	return m.token, m.createErr
+}
+func (m *mockTokens) DeleteToken(_ ssh_client.SSHRun, id string) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+func (m *mockTokens) ListTokens(ssh_client.SSHRun) ([]string, error) { return m.ids, nil }
+func (m *mockTokens) RotateToken(_ ssh_client.SSHClient, token string) error {
+	m.rotatedTo = token
+	return m.rotateErr
+}
+func (m *mockTokens) UpdateToken(client ssh_client.SSHClient, _ string) error {
+	m.updated = append(m.updated, client.HostnameOrIpAddress())
+	return nil
+}
+
+type mockTokenSSH struct {
+	host string
+}
+
+func (m mockTokenSSH) SshClient(context.Context) (ssh_client.SSHClient, error) {
+	return &mockSSH{hostname: m.host}, nil
+}
+func (m mockTokenSSH) ForHost(host string) handlers.K3sTypeSSH {
+	return mockTokenSSH{host: host}
+}
+
+func rotation(t *testing.T, newToken types.String, servers ...string) types.Object {
+	hosts, _ := types.ListValueFrom(t.Context(), types.StringType, servers)
+	r := handlers.TokenRotation{NewToken: newToken, Servers: hosts}
+	return r.ToObject(t.Context())
+}
+
+func TestTokenHandlerCreate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Bootstrap token", func(t *testing.T) {
+		data := handlers.TokenClientModel{Rotate: types.ObjectNull(handlers.TokenRotation{}.AttributeTypes())}
+		server := mockTokens{token: "K10abc::abcdef.0123456789abcdef"}
+		if err := data.Create(t.Context(), mockTokenSSH{host: "server"}, &server); err != nil {
+			t.Fatalf("Good create shouldn't raise, got %s", err.Error())
+		}
+		if !data.Id.Equal(types.StringValue("abcdef")) {
+			t.Errorf("Expected id abcdef, got %s", data.Id)
+		}
+	})
+
+	t.Run("Bad bootstrap token", func(t *testing.T) {
+		data := handlers.TokenClientModel{Rotate: types.ObjectNull(handlers.TokenRotation{}.AttributeTypes())}
+		if err := data.Create(t.Context(), mockTokenSSH{}, &mockTokens{createErr: fmt.Errorf("error")}); err == nil {
+			t.Errorf("Bad create should raise, got nil")
+		}
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		data := handlers.TokenClientModel{Rotate: rotation(t, types.StringUnknown(), "server-2", "server-3")}
+		server := mockTokens{}
+		if err := data.Create(t.Context(), mockTokenSSH{host: "server-1"}, &server); err != nil {
+			t.Fatalf("Good rotation shouldn't raise, got %s", err.Error())
+		}
+		if server.rotatedTo == "" || !data.Token.Equal(types.StringValue(server.rotatedTo)) {
+			t.Errorf("Expected token to be the generated token, got %s", data.Token)
+		}
+		if fmt.Sprint(server.updated) != "[server-1 server-2 server-3]" {
+			t.Errorf("Expected all servers to be updated, got %v", server.updated)
+		}
+	})
+
+	t.Run("Bad rotation", func(t *testing.T) {
+		data := handlers.TokenClientModel{Rotate: rotation(t, types.StringValue("new"))}
+		if err := data.Create(t.Context(), mockTokenSSH{}, &mockTokens{rotateErr: fmt.Errorf("error")}); err == nil {
+			t.Errorf("Bad rotation should raise, got nil")
+		}
+	})
+}
+
+func TestTokenHandlerRead(t *testing.T) {
+	t.Parallel()
+
+	data := handlers.TokenClientModel{
+		Id:     types.StringValue("abcdef"),
+		Rotate: types.ObjectNull(handlers.TokenRotation{}.AttributeTypes()),
+	}
+
+	exists, err := data.Read(t.Context(), mockTokenSSH{}, &mockTokens{ids: []string{"abcdef"}})
+	if err != nil || !exists {
+		t.Errorf("Expected token to exist, got %v %v", exists, err)
+	}
+
+	exists, err = data.Read(t.Context(), mockTokenSSH{}, &mockTokens{ids: []string{"123456"}})
+	if err != nil || exists {
+		t.Errorf("Expected token to be missing, got %v %v", exists, err)
+	}
+}
+
+func TestTokenHandlerUpdate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Added server", func(t *testing.T) {
+		state := handlers.TokenClientModel{Rotate: rotation(t, types.StringValue("new"), "server-2")}
+		server := mockTokens{}
+		err := state.Update(t.Context(), handlers.TokenClientModel{Rotate: rotation(t, types.StringValue("new"), "server-2", "server-3")}, mockTokenSSH{}, &server)
+		if err != nil {
+			t.Fatalf("Good update shouldn't raise, got %s", err.Error())
+		}
+		if server.rotatedTo != "" {
+			t.Errorf("Same token shouldn't rotate")
+		}
+		if fmt.Sprint(server.updated) != "[server-3]" {
+			t.Errorf("Expected only the new server to be updated, got %v", server.updated)
+		}
+	})
+
+	t.Run("New token", func(t *testing.T) {
+		state := handlers.TokenClientModel{Rotate: rotation(t, types.StringValue("old"))}
+		server := mockTokens{}
+		err := state.Update(t.Context(), handlers.TokenClientModel{Rotate: rotation(t, types.StringValue("new"))}, mockTokenSSH{}, &server)
+		if err != nil {
+			t.Fatalf("Good update shouldn't raise, got %s", err.Error())
+		}
+		if server.rotatedTo != "new" || !state.Token.Equal(types.StringValue("new")) {
+			t.Errorf("Expected rotation to new token, got %s", server.rotatedTo)
+		}
+	})
+}
+
+func TestTokenHandlerDelete(t *testing.T) {
+	t.Parallel()
+
+	data := handlers.TokenClientModel{
+		Id:     types.StringValue("abcdef"),
+		Rotate: types.ObjectNull(handlers.TokenRotation{}.AttributeTypes()),
+	}
+	server := mockTokens{}
+	if err := data.Delete(t.Context(), mockTokenSSH{}, &server); err != nil {
+		t.Fatalf("Good delete shouldn't raise, got %s", err.Error())
+	}
+	if fmt.Sprint(server.deleted) != "[abcdef]" {
+		t.Errorf("Expected token abcdef to be deleted, got %v", server.deleted)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -15,6 +16,13 @@ type K3sKubeConfig struct {
 	KubeConfig  types.String `tfsdk:"kubeconfig"`
 	Hostname    types.String `tfsdk:"hostname"`
 	AllowEmpty  types.Bool   `tfsdk:"allow_empty"`
+	// Customizations of the rendered kubeconfig
+	ContextName          types.String `tfsdk:"context_name"`
+	ClusterName          types.String `tfsdk:"cluster_name"`
+	UserName             types.String `tfsdk:"user_name"`
+	Port                 types.Int64  `tfsdk:"port"`
+	TlsServerName        types.String `tfsdk:"tls_server_name"`
+	CertificateAuthority types.String `tfsdk:"certificate_authority"`
 }
 
 type KubeConfig struct {
@@ -38,6 +46,10 @@ type TK3SServerRead interface {
 // Performs the read operation on the k3s_kubeconfig data source. This method allows testable decoupling from
 // terraform operations.
 func (s *K3sKubeConfig) Read(ctx context.Context, auth TKubeConfigRead, server TK3SServerRead) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
 	sshClient, err := auth.SshClient(ctx)
 	if err != nil {
 		return fmt.Errorf("creating ssh config: %s", err.Error())
@@ -58,9 +70,8 @@ func (s *K3sKubeConfig) Read(ctx context.Context, auth TKubeConfigRead, server T
 		return fmt.Errorf("parsing kubeconfig: %s", err.Error())
 	}
 
-	// Set hostname
-	if !s.Hostname.IsNull() {
-		clusterAuth.UpdateHost(s.Hostname.ValueString())
+	if err := s.customize(&clusterAuth); err != nil {
+		return fmt.Errorf("customizing kubeconfig: %s", err.Error())
 	}
 
 	s.Auth = auth.ToObject(ctx)
@@ -70,6 +81,41 @@ func (s *K3sKubeConfig) Read(ctx context.Context, auth TKubeConfigRead, server T
 	return nil
 }
 
+func (s K3sKubeConfig) Validate() error {
+	if !s.Port.IsNull() && !s.Port.IsUnknown() && (s.Port.ValueInt64() < 1 || s.Port.ValueInt64() > 65535) {
+		return fmt.Errorf("port must be between 1 and 65535, got %d", s.Port.ValueInt64())
+	}
+	if !s.CertificateAuthority.IsNull() && !s.CertificateAuthority.IsUnknown() {
+		if block, _ := pem.Decode([]byte(s.CertificateAuthority.ValueString())); block == nil {
+			return fmt.Errorf("certificate_authority must be a PEM encoded certificate")
+		}
+	}
+	return nil
+}
+
+// Applies the endpoint and naming overrides to the server's kubeconfig.
+func (s *K3sKubeConfig) customize(clusterAuth *ClusterAuth) error {
+	// Set hostname
+	if !s.Hostname.IsNull() {
+		if err := clusterAuth.UpdateHost(s.Hostname.ValueString()); err != nil {
+			return err
+		}
+	}
+	if !s.Port.IsNull() {
+		if err := clusterAuth.UpdatePort(s.Port.ValueInt64()); err != nil {
+			return err
+		}
+	}
+	if !s.TlsServerName.IsNull() {
+		clusterAuth.UpdateTlsServerName(s.TlsServerName.ValueString())
+	}
+	if !s.CertificateAuthority.IsNull() {
+		clusterAuth.UpdateCertificateAuthority(s.CertificateAuthority.ValueString())
+	}
+	clusterAuth.Rename(s.ContextName.ValueString(), s.ClusterName.ValueString(), s.UserName.ValueString())
+	return nil
+}
+
 func (s *K3sKubeConfig) setDefaults() {
 	s.Auth = DefaultNodeAuth()
 	s.ClusterAuth = DefaultK3sClusterAuth()
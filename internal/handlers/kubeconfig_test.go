@@ -3,10 +3,12 @@ package handlers_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"k8s.io/client-go/tools/clientcmd"
 	"striveworks.us/terraform-provider-k3s/internal/handlers"
 	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
 )
@@ -149,4 +151,68 @@ func TestKubecConfigHandlerRead(t *testing.T) {
 			t.Errorf("Bad resync shouldn't raise, got err: %s", err.Error())
 		}
 	})
+
+	t.Run("Customized", func(t *testing.T) {
+		ca := strings.ReplaceAll(expectedCA, `\n`, "\n")
+		data := handlers.K3sKubeConfig{
+			Hostname:             types.StringValue("lb.example.com"),
+			Port:                 types.Int64Value(443),
+			TlsServerName:        types.StringValue("kubernetes"),
+			CertificateAuthority: types.StringValue(ca),
+			ContextName:          types.StringValue("prod"),
+			ClusterName:          types.StringValue("prod-cluster"),
+			UserName:             types.StringValue("prod-admin"),
+		}
+		if err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockKubeconfigGoodResync{}); err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+
+		config, err := clientcmd.Load([]byte(data.KubeConfig.ValueString()))
+		if err != nil {
+			t.Fatalf("Expected a valid kubeconfig, got %v", err.Error())
+		}
+		current := config.Contexts["prod"]
+		if config.CurrentContext != "prod" || current == nil || current.Cluster != "prod-cluster" || current.AuthInfo != "prod-admin" {
+			t.Fatalf("Expected renamed context, cluster and user, got %v", config.Contexts)
+		}
+		if _, ok := config.Clusters["default"]; ok {
+			t.Errorf("Expected the default cluster to be renamed")
+		}
+		cluster := config.Clusters["prod-cluster"]
+		if cluster.Server != "https://lb.example.com:443" || cluster.TLSServerName != "kubernetes" || string(cluster.CertificateAuthorityData) != ca {
+			t.Errorf("Unexpected cluster %v", cluster)
+		}
+		if len(config.AuthInfos["prod-admin"].ClientKeyData) == 0 {
+			t.Errorf("Expected the user credentials to be kept")
+		}
+		if got := data.ClusterAuth.Attributes()["server"]; !got.Equal(types.StringValue("https://lb.example.com:443")) {
+			t.Errorf("Expected cluster_auth to follow the endpoint, got %s", got)
+		}
+	})
+
+	t.Run("Non default names", func(t *testing.T) {
+		renamed := strings.NewReplacer("name: default", "name: prod", "cluster: default", "cluster: prod",
+			"user: default", "user: prod", "current-context: default", "current-context: prod").Replace(TestMockKubeconfig)
+		clusterAuth, err := handlers.BuildClusterAuth(renamed)
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		if err := clusterAuth.UpdateHost("10.0.0.1"); err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		if !clusterAuth.Server.Equal(types.StringValue("https://10.0.0.1:6443")) {
+			t.Errorf("Expected https://10.0.0.1:6443, got %s", clusterAuth.Server)
+		}
+
+		if _, err := handlers.BuildClusterAuth(strings.ReplaceAll(TestMockKubeconfig, "current-context: default", "current-context: other")); err == nil {
+			t.Errorf("Expected an error for a missing context, got nil")
+		}
+	})
+
+	t.Run("Bad port", func(t *testing.T) {
+		data := handlers.K3sKubeConfig{Port: types.Int64Value(70000)}
+		if err := data.Read(t.Context(), &mockKubeconfigGoodSSH{}, &mockKubeconfigGoodResync{}); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
 }
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+type MergedKubeConfig struct {
+	KubeConfigs    types.List   `tfsdk:"kubeconfigs"`
+	CurrentContext types.String `tfsdk:"current_context"`
+	KubeConfig     types.String `tfsdk:"kubeconfig"`
+}
+
+type NamedKubeConfig struct {
+	Name       types.String `tfsdk:"name"`
+	KubeConfig types.String `tfsdk:"kubeconfig"`
+}
+
+func (NamedKubeConfig) Schema() schema.Attribute {
+	return schema.ListNestedAttribute{
+		Required:    true,
+		Description: "Kubeconfigs to merge, the current context of each is kept",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					Required:            true,
+					MarkdownDescription: "Name of the context, cluster and user of this kubeconfig in the merged one",
+				},
+				"kubeconfig": schema.StringAttribute{
+					Required:            true,
+					Sensitive:           true,
+					MarkdownDescription: "Kubeconfig to merge, such as the kubeconfig of a k3s_server resource",
+				},
+			},
+		},
+	}
+}
+
+func (NamedKubeConfig) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"name":       types.StringType,
+		"kubeconfig": types.StringType,
+	}
+}
+
+// Performs the read operation on the k3s_merged_kubeconfig data source.
+func (m *MergedKubeConfig) Read(ctx context.Context) error {
+	var entries []NamedKubeConfig
+	if diags := m.KubeConfigs.ElementsAs(ctx, &entries, false); diags.HasError() {
+		return fmt.Errorf("reading kubeconfigs")
+	}
+
+	named := make([]k3s.NamedKubeConfig, 0, len(entries))
+	for _, entry := range entries {
+		named = append(named, k3s.NamedKubeConfig{Name: entry.Name.ValueString(), KubeConfig: entry.KubeConfig.ValueString()})
+	}
+
+	kubeconfig, err := k3s.MergeKubeConfigs(named, m.CurrentContext.ValueString())
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Merged %d kubeconfigs", len(named)))
+
+	m.KubeConfig = types.StringValue(kubeconfig)
+	return nil
+}
@@ -0,0 +1,36 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+func TestMergedKubeConfigRead(t *testing.T) {
+	t.Parallel()
+
+	entry := func(name string) attr.Value {
+		return types.ObjectValueMust(handlers.NamedKubeConfig{}.AttributeTypes(), map[string]attr.Value{
+			"name":       types.StringValue(name),
+			"kubeconfig": types.StringValue(TestMockKubeconfig),
+		})
+	}
+	data := handlers.MergedKubeConfig{
+		KubeConfigs:    types.ListValueMust(types.ObjectType{AttrTypes: handlers.NamedKubeConfig{}.AttributeTypes()}, []attr.Value{entry("a"), entry("b")}),
+		CurrentContext: types.StringNull(),
+	}
+	if err := data.Read(t.Context()); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+
+	config, err := clientcmd.Load([]byte(data.KubeConfig.ValueString()))
+	if err != nil {
+		t.Fatalf("Expected a valid kubeconfig, got %v", err.Error())
+	}
+	if config.CurrentContext != "a" || len(config.Contexts) != 2 {
+		t.Errorf("Expected contexts a and b with a current, got %v", config.Contexts)
+	}
+}
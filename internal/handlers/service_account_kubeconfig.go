@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	api "k8s.io/client-go/tools/clientcmd/api"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+// Shortest lifetime the TokenRequest api accepts.
+const minServiceAccountTtl = 10 * time.Minute
+
+type ServiceAccountKubeConfigModel struct {
+	ServerKubeConfig types.String `tfsdk:"server_kubeconfig"`
+	Name             types.String `tfsdk:"name"`
+	Namespace        types.String `tfsdk:"namespace"`
+	// Role bound to, only one of the two
+	ClusterRole types.String `tfsdk:"cluster_role"`
+	Role        types.String `tfsdk:"role"`
+	ClusterWide types.Bool   `tfsdk:"cluster_wide"`
+	Ttl         types.String `tfsdk:"ttl"`
+	// Outputs
+	Id         types.String `tfsdk:"id"`
+	Token      types.String `tfsdk:"token"`
+	ExpiresAt  types.String `tfsdk:"expires_at"`
+	KubeConfig types.String `tfsdk:"kubeconfig"`
+}
+
+func (s ServiceAccountKubeConfigModel) Validate() error {
+	if s.ClusterRole.IsNull() == s.Role.IsNull() {
+		return fmt.Errorf("exactly one of cluster_role or role must be passed")
+	}
+	if !s.Role.IsNull() && s.ClusterWide.ValueBool() {
+		return fmt.Errorf("a role is namespaced, only a cluster_role can be bound cluster wide")
+	}
+	if !s.Ttl.IsNull() && !s.Ttl.IsUnknown() {
+		ttl, err := time.ParseDuration(s.Ttl.ValueString())
+		if err != nil {
+			return fmt.Errorf("ttl %s is not a duration: %s", s.Ttl.ValueString(), err.Error())
+		}
+		if ttl < minServiceAccountTtl {
+			return fmt.Errorf("ttl must be at least %s, got %s", minServiceAccountTtl, ttl)
+		}
+	}
+	return nil
+}
+
+func (s ServiceAccountKubeConfigModel) account() k3s.ServiceAccount {
+	account := k3s.ServiceAccount{
+		Name:        s.Name.ValueString(),
+		Namespace:   s.Namespace.ValueString(),
+		Role:        s.ClusterRole.ValueString(),
+		RoleKind:    "ClusterRole",
+		ClusterWide: s.ClusterWide.ValueBool(),
+	}
+	if !s.Role.IsNull() {
+		account.Role = s.Role.ValueString()
+		account.RoleKind = "Role"
+	}
+	return account
+}
+
+func (s ServiceAccountKubeConfigModel) ttl() time.Duration {
+	ttl, err := time.ParseDuration(s.Ttl.ValueString())
+	if err != nil {
+		return time.Hour
+	}
+	return ttl
+}
+
+// Whether the token is past its expiry and needs to be requested again.
+func (s ServiceAccountKubeConfigModel) Expired(now time.Time) bool {
+	expiresAt, err := time.Parse(time.RFC3339, s.ExpiresAt.ValueString())
+	if err != nil {
+		return false
+	}
+	return !now.Before(expiresAt)
+}
+
+func (s *ServiceAccountKubeConfigModel) Create(ctx context.Context, accounts k3s.ServiceAccounts) error {
+	account := s.account()
+	if err := accounts.CreateServiceAccount(account); err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "service account created")
+
+	if err := s.requestToken(ctx, accounts); err != nil {
+		return err
+	}
+
+	s.Id = types.StringValue(fmt.Sprintf("%s/%s", account.Namespace, account.Name))
+	return nil
+}
+
+// Returns false when the service account no longer exists.
+func (s *ServiceAccountKubeConfigModel) Read(ctx context.Context, accounts k3s.ServiceAccounts) (bool, error) {
+	exists, err := accounts.ServiceAccountExists(s.account())
+	if err != nil {
+		return false, err
+	}
+	tflog.Debug(ctx, "service account read")
+	return exists, nil
+}
+
+// Requests a new token when the ttl changed or the plan marked the token for renewal.
+// A new server_kubeconfig only renders the kubeconfig again with the current token.
+func (s *ServiceAccountKubeConfigModel) Update(ctx context.Context, inc ServiceAccountKubeConfigModel, accounts k3s.ServiceAccounts) error {
+	renew := inc.Token.IsUnknown()
+	rerender := !s.ServerKubeConfig.Equal(inc.ServerKubeConfig)
+	s.Ttl = inc.Ttl
+	s.ServerKubeConfig = inc.ServerKubeConfig
+	if renew {
+		return s.requestToken(ctx, accounts)
+	}
+	if !rerender {
+		tflog.Debug(ctx, "No change is needed, the token is still valid")
+		return nil
+	}
+
+	kubeconfig, err := s.render(s.Token.ValueString())
+	if err != nil {
+		return err
+	}
+	s.KubeConfig = types.StringValue(kubeconfig)
+	tflog.Debug(ctx, "service account kubeconfig rendered for the new server")
+	return nil
+}
+
+func (s *ServiceAccountKubeConfigModel) Delete(ctx context.Context, accounts k3s.ServiceAccounts) error {
+	if err := accounts.DeleteServiceAccount(s.account()); err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "service account deleted")
+	return nil
+}
+
+func (s *ServiceAccountKubeConfigModel) requestToken(ctx context.Context, accounts k3s.ServiceAccounts) error {
+	token, expiresAt, err := accounts.RequestToken(s.account(), s.ttl())
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "service account token requested")
+
+	kubeconfig, err := s.render(token)
+	if err != nil {
+		return err
+	}
+
+	s.Token = types.StringValue(token)
+	s.ExpiresAt = types.StringValue(expiresAt.UTC().Format(time.RFC3339))
+	s.KubeConfig = types.StringValue(kubeconfig)
+	return nil
+}
+
+func (s *ServiceAccountKubeConfigModel) render(token string) (string, error) {
+	account := s.account()
+	kubeconfig, err := userKubeConfig(s.ServerKubeConfig.ValueString(), account.Name, account.Namespace, &api.AuthInfo{Token: token})
+	if err != nil {
+		return "", fmt.Errorf("rendering kubeconfig: %s", err.Error())
+	}
+	return kubeconfig, nil
+}
@@ -0,0 +1,178 @@
+package handlers_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+type mockServiceAccounts struct {
+	createErr error
+	exists    bool
+	expiry    time.Time
+
+	created  []k3s.ServiceAccount
+	requests int
+	deleted  []k3s.ServiceAccount
+}
+
+func (m *mockServiceAccounts) CreateServiceAccount(account k3s.ServiceAccount) error {
+	m.created = append(m.created, account)
+	return m.createErr
+}
+func (m *mockServiceAccounts) RequestToken(k3s.ServiceAccount, time.Duration) (string, time.Time, error) {
+	m.requests++
+	return fmt.Sprintf("token-%d", m.requests), m.expiry, nil
+}
+func (m *mockServiceAccounts) ServiceAccountExists(k3s.ServiceAccount) (bool, error) {
+	return m.exists, nil
+}
+func (m *mockServiceAccounts) DeleteServiceAccount(account k3s.ServiceAccount) error {
+	m.deleted = append(m.deleted, account)
+	return nil
+}
+
+func serviceAccountModel() handlers.ServiceAccountKubeConfigModel {
+	return handlers.ServiceAccountKubeConfigModel{
+		ServerKubeConfig: types.StringValue(TestMockKubeconfig),
+		Name:             types.StringValue("ci"),
+		Namespace:        types.StringValue("apps"),
+		ClusterRole:      types.StringValue("edit"),
+		Role:             types.StringNull(),
+		ClusterWide:      types.BoolValue(false),
+		Ttl:              types.StringValue("1h"),
+	}
+}
+
+func TestServiceAccountKubeConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*handlers.ServiceAccountKubeConfigModel)
+		valid  bool
+	}{
+		{"cluster role", func(*handlers.ServiceAccountKubeConfigModel) {}, true},
+		{"role", func(m *handlers.ServiceAccountKubeConfigModel) {
+			m.ClusterRole, m.Role = types.StringNull(), types.StringValue("deployer")
+		}, true},
+		{"no role", func(m *handlers.ServiceAccountKubeConfigModel) { m.ClusterRole = types.StringNull() }, false},
+		{"both roles", func(m *handlers.ServiceAccountKubeConfigModel) { m.Role = types.StringValue("deployer") }, false},
+		{"cluster wide role", func(m *handlers.ServiceAccountKubeConfigModel) {
+			m.ClusterRole, m.Role, m.ClusterWide = types.StringNull(), types.StringValue("deployer"), types.BoolValue(true)
+		}, false},
+		{"short ttl", func(m *handlers.ServiceAccountKubeConfigModel) { m.Ttl = types.StringValue("1m") }, false},
+		{"bad ttl", func(m *handlers.ServiceAccountKubeConfigModel) { m.Ttl = types.StringValue("soon") }, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := serviceAccountModel()
+			tc.modify(&m)
+			if err := m.Validate(); (err == nil) != tc.valid {
+				t.Errorf("Expected valid %v, got %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestServiceAccountKubeConfigCreate(t *testing.T) {
+	t.Parallel()
+
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	accounts := &mockServiceAccounts{expiry: expiry}
+	m := serviceAccountModel()
+
+	if err := m.Create(t.Context(), accounts); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	if len(accounts.created) != 1 || accounts.created[0].RoleKind != "ClusterRole" || accounts.created[0].Role != "edit" {
+		t.Errorf("Expected a service account bound to the edit cluster role, got %v", accounts.created)
+	}
+	if m.Id.ValueString() != "apps/ci" || m.Token.ValueString() != "token-1" || m.ExpiresAt.ValueString() != "2030-01-01T00:00:00Z" {
+		t.Errorf("Unexpected outputs %s %s %s", m.Id, m.Token, m.ExpiresAt)
+	}
+
+	config, err := clientcmd.Load([]byte(m.KubeConfig.ValueString()))
+	if err != nil {
+		t.Fatalf("Expected a valid kubeconfig, got %v", err.Error())
+	}
+	current := config.Contexts[config.CurrentContext]
+	if current == nil || current.Namespace != "apps" || config.AuthInfos[current.AuthInfo].Token != "token-1" {
+		t.Errorf("Expected the service account token in the apps namespace, got %v", current)
+	}
+	if config.Clusters[current.Cluster].Server != "https://127.0.0.1:6443" {
+		t.Errorf("Expected the cluster of the server kubeconfig, got %v", config.Clusters[current.Cluster])
+	}
+	if len(config.AuthInfos[current.AuthInfo].ClientKeyData) != 0 {
+		t.Errorf("Admin credentials leaked into the kubeconfig")
+	}
+
+	t.Run("Create error", func(t *testing.T) {
+		m := serviceAccountModel()
+		if err := m.Create(t.Context(), &mockServiceAccounts{createErr: fmt.Errorf("forbidden")}); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestServiceAccountKubeConfigUpdate(t *testing.T) {
+	t.Parallel()
+
+	accounts := &mockServiceAccounts{expiry: time.Now().Add(time.Hour)}
+	state := serviceAccountModel()
+	if err := state.Create(t.Context(), accounts); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+
+	plan := state
+	if err := state.Update(t.Context(), plan, accounts); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	if accounts.requests != 1 {
+		t.Errorf("Expected the token to be kept, got %d requests", accounts.requests)
+	}
+
+	plan.Ttl = types.StringValue("2h")
+	plan.Token = types.StringUnknown()
+	if err := state.Update(t.Context(), plan, accounts); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	if accounts.requests != 2 || state.Token.ValueString() != "token-2" || state.Ttl.ValueString() != "2h" {
+		t.Errorf("Expected a renewed token, got %s after %d requests", state.Token, accounts.requests)
+	}
+
+	plan = state
+	plan.ServerKubeConfig = types.StringValue(strings.ReplaceAll(state.ServerKubeConfig.ValueString(), "127.0.0.1", "10.0.0.9"))
+	if err := state.Update(t.Context(), plan, accounts); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	config, err := clientcmd.Load([]byte(state.KubeConfig.ValueString()))
+	if err != nil {
+		t.Fatalf("Expected a valid kubeconfig, got %v", err.Error())
+	}
+	current := config.Contexts[config.CurrentContext]
+	if config.Clusters[current.Cluster].Server != "https://10.0.0.9:6443" || config.AuthInfos[current.AuthInfo].Token != "token-2" {
+		t.Errorf("Expected the kubeconfig rendered for the new server with the same token, got %v", config.Clusters[current.Cluster])
+	}
+	if accounts.requests != 2 {
+		t.Errorf("Expected the token to be kept, got %d requests", accounts.requests)
+	}
+}
+
+func TestServiceAccountKubeConfigExpired(t *testing.T) {
+	t.Parallel()
+
+	m := serviceAccountModel()
+	m.ExpiresAt = types.StringValue("2030-01-01T00:00:00Z")
+	if m.Expired(time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected token to still be valid")
+	}
+	if !m.Expired(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected token to be expired")
+	}
+}
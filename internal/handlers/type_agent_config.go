@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+type AgentConfig struct {
+	NodeName       types.String `tfsdk:"node_name"`
+	NodeIp         types.String `tfsdk:"node_ip"`
+	NodeExternalIp types.String `tfsdk:"node_external_ip"`
+	NodeLabels     types.List   `tfsdk:"node_labels"`
+	NodeTaints     types.List   `tfsdk:"node_taints"`
+	KubeletArgs    types.List   `tfsdk:"kubelet_args"`
+	KubeProxyArgs  types.List   `tfsdk:"kube_proxy_args"`
+}
+
+func (m AgentConfig) Schema() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Optional:    true,
+		Description: "Common k3s agent flags, merged on top of `config`. Flags set here take precedence over the same keys in `config`",
+		Attributes: map[string]schema.Attribute{
+			"node_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Node name, `node-name`",
+			},
+			"node_ip": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "IP address to advertise for the node, `node-ip`",
+			},
+			"node_external_ip": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "External IP address to advertise for the node, `node-external-ip`",
+			},
+			"node_labels": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Labels to register the node with, e.g. `pool=workers`, `node-label`",
+			},
+			"node_taints": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Taints to register the node with, e.g. `gpu=true:NoSchedule`, `node-taint`",
+			},
+			"kubelet_args": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Customized flags for the kubelet process, `kubelet-arg`",
+			},
+			"kube_proxy_args": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Customized flags for the kube-proxy process, `kube-proxy-arg`",
+			},
+		},
+	}
+}
+
+func NewAgentConfig(ctx context.Context, t basetypes.ObjectValue) AgentConfig {
+	var na AgentConfig
+	t.As(ctx, &na, basetypes.ObjectAsOptions{})
+	return na
+}
+
+func (m *AgentConfig) ToObject(ctx context.Context) basetypes.ObjectValue {
+	return ToObject(ctx, m)
+}
+
+func (m AgentConfig) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"node_name":        types.StringType,
+		"node_ip":          types.StringType,
+		"node_external_ip": types.StringType,
+		"node_labels":      types.ListType{ElemType: types.StringType},
+		"node_taints":      types.ListType{ElemType: types.StringType},
+		"kubelet_args":     types.ListType{ElemType: types.StringType},
+		"kube_proxy_args":  types.ListType{ElemType: types.StringType},
+	}
+}
+
+// The k3s config entries of the set flags.
+func (m AgentConfig) config(ctx context.Context) map[any]any {
+	return configEntries(ctx, map[string]attr.Value{
+		"node-name":        m.NodeName,
+		"node-ip":          m.NodeIp,
+		"node-external-ip": m.NodeExternalIp,
+		"node-label":       m.NodeLabels,
+		"node-taint":       m.NodeTaints,
+		"kubelet-arg":      m.KubeletArgs,
+		"kube-proxy-arg":   m.KubeProxyArgs,
+	})
+}
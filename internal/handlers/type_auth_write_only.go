@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ssh credentials that are never persisted to state. Terraform only sends
+// write-only values on create and update, so refreshes and deletes need the
+// provider's default_auth to reach the node.
+type AuthWriteOnly struct {
+	PrivateKey types.String `tfsdk:"private_key"`
+	Password   types.String `tfsdk:"password"`
+}
+
+func (m AuthWriteOnly) Schema() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Optional:  true,
+		WriteOnly: true,
+		MarkdownDescription: "Write-only ssh credentials, in place of the ones in `auth`, never stored in state. " +
+			"Only available on create and update, refreshing and destroying the node falls back to the provider's `default_auth`. " +
+			"Bump `auth_wo_version` to apply new credentials",
+		Attributes: map[string]schema.Attribute{
+			"private_key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				MarkdownDescription: "Value of a private key used to auth",
+			},
+			"password": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				MarkdownDescription: "Value of a password used to auth",
+			},
+		},
+	}
+}
+
+func NewAuthWriteOnly(ctx context.Context, t basetypes.ObjectValue) AuthWriteOnly {
+	var na AuthWriteOnly
+	t.As(ctx, &na, basetypes.ObjectAsOptions{})
+	return na
+}
+
+func (m AuthWriteOnly) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"private_key": types.StringType,
+		"password":    types.StringType,
+	}
+}
+
+func (m AuthWriteOnly) Validate() error {
+	if !m.PrivateKey.IsNull() && !m.Password.IsNull() {
+		return fmt.Errorf("both password and private key were passed, only pass one")
+	}
+	return nil
+}
+
+// Write-only values never show up in a plan, a change to them is applied
+// by bumping this counter alongside.
+func WriteOnlyVersionSchema(attribute string, modifiers ...planmodifier.Int64) schema.Attribute {
+	return schema.Int64Attribute{
+		Optional:            true,
+		MarkdownDescription: fmt.Sprintf("Version of `%s`, change it to apply a new value", attribute),
+		PlanModifiers:       modifiers,
+	}
+}
@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+func authWriteOnly(t *testing.T, privateKey types.String, password types.String) types.Object {
+	obj, diags := types.ObjectValue(handlers.AuthWriteOnly{}.AttributeTypes(), map[string]attr.Value{
+		"private_key": privateKey,
+		"password":    password,
+	})
+	if diags.HasError() {
+		t.Fatalf("building auth_wo: %v", diags)
+	}
+	return obj
+}
+
+func TestNodeAuthWriteOnly(t *testing.T) {
+	t.Parallel()
+
+	auth := handlers.NodeAuth{
+		Host:       types.StringValue("192.168.1.2"),
+		Port:       types.Int32Null(),
+		User:       types.StringValue("ubuntu"),
+		PrivateKey: types.StringNull(),
+		Password:   types.StringNull(),
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		wo := auth.WithWriteOnly(t.Context(), authWriteOnly(t, types.StringValue("abc123"), types.StringNull()))
+		if err := wo.Validate(); err != nil {
+			t.Errorf("Write-only key alone shouldn't raise, got %s", err.Error())
+		}
+	})
+
+	t.Run("Both auth and auth_wo", func(t *testing.T) {
+		both := auth
+		both.Password = types.StringValue("hunter2")
+		wo := both.WithWriteOnly(t.Context(), authWriteOnly(t, types.StringValue("abc123"), types.StringNull()))
+		if err := wo.Validate(); err == nil {
+			t.Errorf("Credentials in both auth and auth_wo should raise, got nil")
+		}
+	})
+
+	t.Run("Key and password", func(t *testing.T) {
+		wo := auth.WithWriteOnly(t.Context(), authWriteOnly(t, types.StringValue("abc123"), types.StringValue("hunter2")))
+		if err := wo.Validate(); err == nil {
+			t.Errorf("Both a write-only key and password should raise, got nil")
+		}
+	})
+
+	t.Run("Never in state", func(t *testing.T) {
+		wo := auth.WithWriteOnly(t.Context(), authWriteOnly(t, types.StringValue("abc123"), types.StringNull()))
+		state := handlers.NewNodeAuth(t.Context(), wo.ToObject(t.Context()))
+		if !state.PrivateKey.IsNull() || !state.Password.IsNull() {
+			t.Errorf("Write-only credentials leaked into the object, got %s %s", state.PrivateKey, state.Password)
+		}
+	})
+}
+
+func TestServerSetWriteOnly(t *testing.T) {
+	t.Parallel()
+
+	ha := handlers.HaConfig{
+		ClusterInit:    types.BoolValue(false),
+		Token:          types.StringNull(),
+		AgentToken:     types.StringNull(),
+		Server:         types.StringValue("https://192.168.1.2:6443"),
+		TokenWo:        types.StringNull(),
+		TokenWoVersion: types.Int64Value(1),
+	}
+	plan := handlers.ServerClientModel{
+		AuthWo:          types.ObjectNull(handlers.AuthWriteOnly{}.AttributeTypes()),
+		HaConfig:        ha.ToObject(t.Context()),
+		OidcConfig:      types.ObjectNull(handlers.OidcConfig{}.AttributeTypes()),
+		DatastoreConfig: types.ObjectNull(handlers.DatastoreConfig{}.AttributeTypes()),
+	}
+
+	ha.TokenWo = types.StringValue("secret")
+	config := plan
+	config.HaConfig = ha.ToObject(t.Context())
+	config.AuthWo = authWriteOnly(t, types.StringValue("abc123"), types.StringNull())
+
+	if err := handlers.NewHaConfig(t.Context(), config.HaConfig).Validate(); err != nil {
+		t.Errorf("Write-only token shouldn't raise, got %s", err.Error())
+	}
+
+	plan.SetWriteOnly(t.Context(), config)
+	if plan.AuthWo.IsNull() {
+		t.Errorf("Expected auth_wo from the config")
+	}
+
+	server, err := plan.ToServer(t.Context())
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	if server.Config()["token"] != "secret" {
+		t.Errorf("Expected the write-only token in the config, got %v", server.Config()["token"])
+	}
+}
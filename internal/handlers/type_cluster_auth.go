@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -13,6 +14,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"k8s.io/client-go/tools/clientcmd"
 	api "k8s.io/client-go/tools/clientcmd/api"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
 )
 
 type ClusterAuth struct {
@@ -101,28 +103,137 @@ func BuildClusterAuth(kubeconfig string) (ClusterAuth, error) {
 		return data, err
 	}
 
+	cluster, user, err := k3s.CurrentKubeConfigEntries(config)
+	if err != nil {
+		return data, err
+	}
+
 	data.config = config
 	// Set host
-	data.Server = types.StringValue(config.Clusters["default"].Server)
+	data.Server = types.StringValue(cluster.Server)
 	// Set cluster CA
-	data.CertificateAuthorityData = types.StringValue(string(config.Clusters["default"].CertificateAuthorityData))
+	data.CertificateAuthorityData = types.StringValue(string(cluster.CertificateAuthorityData))
 	// Set User cert
-	data.ClientCertificateData = types.StringValue(string(config.AuthInfos["default"].ClientCertificateData))
+	data.ClientCertificateData = types.StringValue(string(user.ClientCertificateData))
 	// Set User Key
-	data.ClientKeyData = types.StringValue(string(config.AuthInfos["default"].ClientKeyData))
+	data.ClientKeyData = types.StringValue(string(user.ClientKeyData))
 
 	return data, nil
 }
 
-func (c *ClusterAuth) UpdateHost(newHost string) {
-	this := *c.config.Clusters["default"]
-	this.Server = fmt.Sprintf("https://%s:6443", newHost)
-	c.config.Clusters["default"] = &this
+// Cluster of the current context, BuildClusterAuth ensures it exists.
+func (c *ClusterAuth) cluster() *api.Cluster {
+	cluster, _, _ := k3s.CurrentKubeConfigEntries(c.config)
+	return cluster
+}
+
+// Points the api server at another host, keeping its port.
+func (c *ClusterAuth) UpdateHost(newHost string) error {
+	cluster := c.cluster()
+	server, err := k3s.ReplaceServerHost(cluster.Server, newHost)
+	if err != nil {
+		return err
+	}
+	cluster.Server = server
+	c.Server = types.StringValue(server)
+	return nil
+}
+
+// Points the api server at another url, such as a load balancer's.
+func (c *ClusterAuth) UpdateServer(server string) error {
+	parsed, err := url.Parse(server)
+	if err != nil {
+		return fmt.Errorf("parsing server %s: %s", server, err.Error())
+	}
+	if parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("server must be an https url, got %s", server)
+	}
+	c.cluster().Server = server
+	c.Server = types.StringValue(server)
+	return nil
+}
 
-	c.Server = types.StringValue(c.config.Clusters["default"].Server)
+// Points the api server at another port, such as the one of a load balancer.
+func (c *ClusterAuth) UpdatePort(port int64) error {
+	cluster := c.cluster()
+	server, err := k3s.ReplaceServerPort(cluster.Server, port)
+	if err != nil {
+		return err
+	}
+	cluster.Server = server
+	c.Server = types.StringValue(server)
+	return nil
+}
+
+// Name to verify the api server's certificate against, when the host isn't in its SANs.
+func (c *ClusterAuth) UpdateTlsServerName(name string) {
+	c.cluster().TLSServerName = name
+}
+
+// Replaces the CA the api server is verified with, for endpoints fronted by another certificate.
+func (c *ClusterAuth) UpdateCertificateAuthority(ca string) {
+	c.cluster().CertificateAuthorityData = []byte(ca)
+	c.CertificateAuthorityData = types.StringValue(ca)
+}
+
+// Renames the current context, its cluster and its user so kubeconfigs of
+// several clusters can be merged. Empty names are kept as they are.
+func (c *ClusterAuth) Rename(contextName string, clusterName string, userName string) {
+	currentName, context, _ := k3s.CurrentKubeConfigContext(c.config)
+	config := c.config
+
+	if clusterName != "" && clusterName != context.Cluster {
+		config.Clusters[clusterName] = config.Clusters[context.Cluster]
+		delete(config.Clusters, context.Cluster)
+		context.Cluster = clusterName
+	}
+	if userName != "" && userName != context.AuthInfo {
+		config.AuthInfos[userName] = config.AuthInfos[context.AuthInfo]
+		delete(config.AuthInfos, context.AuthInfo)
+		context.AuthInfo = userName
+	}
+	if contextName != "" && contextName != currentName {
+		delete(config.Contexts, currentName)
+		config.Contexts[contextName] = context
+		config.CurrentContext = contextName
+	}
 }
 
 func (c *ClusterAuth) KubeConfig() string {
 	kubeconfig, _ := clientcmd.Write(*c.config)
 	return string(kubeconfig)
 }
+
+// Kubeconfig for another user against the cluster of the kubeconfig's current context.
+func userKubeConfig(kubeconfig string, user string, namespace string, authInfo *api.AuthInfo) (string, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", err
+	}
+
+	_, current, err := k3s.CurrentKubeConfigContext(config)
+	if err != nil {
+		return "", err
+	}
+	clusterName := current.Cluster
+	cluster, ok := config.Clusters[clusterName]
+	if !ok {
+		return "", fmt.Errorf("cluster %s not found in kubeconfig", clusterName)
+	}
+
+	out := api.NewConfig()
+	out.Clusters[clusterName] = cluster
+	out.AuthInfos[user] = authInfo
+	out.Contexts[user] = &api.Context{
+		Cluster:   clusterName,
+		AuthInfo:  user,
+		Namespace: namespace,
+	}
+	out.CurrentContext = user
+
+	rendered, err := clientcmd.Write(*out)
+	if err != nil {
+		return "", err
+	}
+	return string(rendered), nil
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+type DatastoreConfig struct {
+	Endpoint types.String `tfsdk:"endpoint"`
+	CA       types.String `tfsdk:"ca"`
+	Cert     types.String `tfsdk:"cert"`
+	Key      types.String `tfsdk:"key"`
+	Username types.String `tfsdk:"username"`
+	Password types.String `tfsdk:"password"`
+	Token    types.String `tfsdk:"token"`
+}
+
+func (m DatastoreConfig) Schema() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Optional:    true,
+		Description: "Use an external datastore (PostgreSQL, MySQL or etcd) instead of sqlite or embedded etcd",
+		Attributes: map[string]schema.Attribute{
+			"endpoint": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Datastore endpoint, e.g. `postgres://host:5432/k3s` or `https://etcd-1:2379,https://etcd-2:2379`",
+			},
+			"ca": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "PEM encoded CA used to verify the datastore. This is the certificate itself, not a path, it is uploaded to the node and passed as `datastore-cafile`",
+			},
+			"cert": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "PEM encoded client certificate used to authenticate to the datastore. This is the certificate itself, not a path, it is uploaded to the node and passed as `datastore-certfile`",
+			},
+			"key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "PEM encoded client key used to authenticate to the datastore. This is the key itself, not a path, it is uploaded to the node and passed as `datastore-keyfile`",
+			},
+			"username": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Username added to the endpoint url",
+			},
+			"password": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Password added to the endpoint url",
+			},
+			"token": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Server token of the first server, required when joining servers sharing the datastore",
+			},
+		},
+	}
+}
+
+func NewDatastoreConfig(ctx context.Context, t basetypes.ObjectValue) DatastoreConfig {
+	var na DatastoreConfig
+	t.As(ctx, &na, basetypes.ObjectAsOptions{})
+	return na
+}
+
+func (m *DatastoreConfig) ToObject(ctx context.Context) basetypes.ObjectValue {
+	return ToObject(ctx, m)
+}
+
+func (m DatastoreConfig) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"endpoint": types.StringType,
+		"ca":       types.StringType,
+		"cert":     types.StringType,
+		"key":      types.StringType,
+		"username": types.StringType,
+		"password": types.StringType,
+		"token":    types.StringType,
+	}
+}
+
+func (m DatastoreConfig) Validate() error {
+	if m.Cert.IsNull() != m.Key.IsNull() {
+		return fmt.Errorf("cert and key must be passed together")
+	}
+	if !m.Password.IsNull() && m.Username.IsNull() {
+		return fmt.Errorf("password requires a username")
+	}
+	// The credentials are added after the scheme, there's nowhere to put them without one
+	if !m.Username.IsNull() && !m.Endpoint.IsUnknown() && !strings.Contains(m.Endpoint.ValueString(), "://") {
+		return fmt.Errorf("username and password require an endpoint with a scheme, such as postgres://")
+	}
+	return nil
+}
+
+// The datastore endpoint with the username and password, if any, added after the scheme.
+func (m DatastoreConfig) endpoint() string {
+	endpoint := m.Endpoint.ValueString()
+	if m.Username.IsNull() {
+		return endpoint
+	}
+
+	var userinfo *url.Userinfo
+	if m.Password.IsNull() {
+		userinfo = url.User(m.Username.ValueString())
+	} else {
+		userinfo = url.UserPassword(m.Username.ValueString(), m.Password.ValueString())
+	}
+
+	scheme, rest, found := strings.Cut(endpoint, "://")
+	if !found {
+		return endpoint
+	}
+	return fmt.Sprintf("%s://%s@%s", scheme, userinfo.String(), rest)
+}
+
+type tDatastoreServer interface {
+	k3s.ServerDatastore
+	k3s.ServerHaMode
+}
+
+func (m DatastoreConfig) configureServer(server tDatastoreServer) {
+	server.AddDatastore(
+		m.endpoint(),
+		m.CA.ValueString(),
+		m.Cert.ValueString(),
+		m.Key.ValueString(),
+	)
+	if !m.Token.IsNull() {
+		server.AddHA(false, m.Token.ValueString(), "")
+	}
+}
@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+func TestDatastoreConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Cert without key", func(t *testing.T) {
+		cfg := handlers.DatastoreConfig{
+			Endpoint: types.StringValue("https://etcd:2379"),
+			Cert:     types.StringValue("cert"),
+			Key:      types.StringNull(),
+			Username: types.StringNull(),
+			Password: types.StringNull(),
+		}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Cert without key should raise, got nil")
+		}
+	})
+
+	t.Run("Password without username", func(t *testing.T) {
+		cfg := handlers.DatastoreConfig{
+			Endpoint: types.StringValue("postgres://db:5432/k3s"),
+			Cert:     types.StringNull(),
+			Key:      types.StringNull(),
+			Username: types.StringNull(),
+			Password: types.StringValue("secret"),
+		}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Password without username should raise, got nil")
+		}
+	})
+
+	t.Run("Credentials without scheme", func(t *testing.T) {
+		cfg := handlers.DatastoreConfig{
+			Endpoint: types.StringValue("db:5432/k3s"),
+			Cert:     types.StringNull(),
+			Key:      types.StringNull(),
+			Username: types.StringValue("k3s"),
+			Password: types.StringValue("secret"),
+		}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Credentials on an endpoint without a scheme should raise, got nil")
+		}
+	})
+}
+
+func TestDatastoreConfigureServer(t *testing.T) {
+	t.Parallel()
+
+	cfg := handlers.DatastoreConfig{
+		Endpoint: types.StringValue("postgres://db:5432/k3s"),
+		CA:       types.StringValue("ca"),
+		Cert:     types.StringNull(),
+		Key:      types.StringNull(),
+		Username: types.StringValue("k3s"),
+		Password: types.StringValue("p@ss"),
+		Token:    types.StringValue("token"),
+	}
+	data := handlers.ServerClientModel{
+		DatastoreConfig: cfg.ToObject(t.Context()),
+		HaConfig:        types.ObjectNull(handlers.HaConfig{}.AttributeTypes()),
+		OidcConfig:      types.ObjectNull(handlers.OidcConfig{}.AttributeTypes()),
+	}
+
+	server, err := data.ToServer(t.Context())
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+
+	config := server.Config()
+	if config["datastore-endpoint"] != "postgres://k3s:p%40ss@db:5432/k3s" {
+		t.Errorf("Unexpected datastore-endpoint %v", config["datastore-endpoint"])
+	}
+	if config["datastore-cafile"] != "/etc/rancher/k3s/tls/datastore-ca.crt" {
+		t.Errorf("Unexpected datastore-cafile %v", config["datastore-cafile"])
+	}
+	if _, ok := config["datastore-certfile"]; ok {
+		t.Errorf("datastore-certfile should not be set without a cert")
+	}
+	if config["token"] != "token" {
+		t.Errorf("Unexpected token %v", config["token"])
+	}
+}
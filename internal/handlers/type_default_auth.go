@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	tftypes "github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+// Provider level ssh settings, used by every node auth that leaves them unset.
+// Each setting falls back to an env var when unset in the provider block.
+type DefaultAuth struct {
+	User       tftypes.String `tfsdk:"user"`
+	PrivateKey tftypes.String `tfsdk:"private_key"`
+	Password   tftypes.String `tfsdk:"password"`
+	Port       tftypes.Int32  `tfsdk:"port"`
+	Bastion    tftypes.Object `tfsdk:"bastion"`
+	Timeouts   tftypes.Object `tfsdk:"timeouts"`
+}
+
+type Bastion struct {
+	Host       tftypes.String `tfsdk:"host"`
+	Port       tftypes.Int32  `tfsdk:"port"`
+	User       tftypes.String `tfsdk:"user"`
+	PrivateKey tftypes.String `tfsdk:"private_key"`
+	Password   tftypes.String `tfsdk:"password"`
+}
+
+type SshTimeouts struct {
+	Connect tftypes.String `tfsdk:"connect"`
+	Ready   tftypes.String `tfsdk:"ready"`
+}
+
+// Reads the provider's default_auth, filling unset settings from env vars.
+func NewDefaultAuth(ctx context.Context, t basetypes.ObjectValue) (*DefaultAuth, error) {
+	var d DefaultAuth
+	if !t.IsNull() && !t.IsUnknown() {
+		if diags := t.As(ctx, &d, basetypes.ObjectAsOptions{}); diags.HasError() {
+			return nil, fmt.Errorf("reading default_auth")
+		}
+	}
+
+	var bastion Bastion
+	if !d.Bastion.IsNull() && !d.Bastion.IsUnknown() {
+		if diags := d.Bastion.As(ctx, &bastion, basetypes.ObjectAsOptions{}); diags.HasError() {
+			return nil, fmt.Errorf("reading default_auth bastion")
+		}
+	}
+	var timeouts SshTimeouts
+	if !d.Timeouts.IsNull() && !d.Timeouts.IsUnknown() {
+		if diags := d.Timeouts.As(ctx, &timeouts, basetypes.ObjectAsOptions{}); diags.HasError() {
+			return nil, fmt.Errorf("reading default_auth timeouts")
+		}
+	}
+
+	var err error
+	d.User = orEnv(d.User, "K3S_SSH_USER")
+	d.PrivateKey = orEnv(d.PrivateKey, "K3S_SSH_PRIVATE_KEY")
+	d.Password = orEnv(d.Password, "K3S_SSH_PASSWORD")
+	if d.Port, err = orEnvInt32(d.Port, "K3S_SSH_PORT"); err != nil {
+		return nil, err
+	}
+	bastion.Host = orEnv(bastion.Host, "K3S_SSH_BASTION_HOST")
+	bastion.User = orEnv(bastion.User, "K3S_SSH_BASTION_USER")
+	bastion.PrivateKey = orEnv(bastion.PrivateKey, "K3S_SSH_BASTION_PRIVATE_KEY")
+	bastion.Password = orEnv(bastion.Password, "K3S_SSH_BASTION_PASSWORD")
+	if bastion.Port, err = orEnvInt32(bastion.Port, "K3S_SSH_BASTION_PORT"); err != nil {
+		return nil, err
+	}
+	timeouts.Connect = orEnv(timeouts.Connect, "K3S_SSH_CONNECT_TIMEOUT")
+	timeouts.Ready = orEnv(timeouts.Ready, "K3S_SSH_READY_TIMEOUT")
+
+	d.Bastion = tftypes.ObjectNull(Bastion{}.AttributeTypes())
+	if !bastion.Host.IsNull() {
+		d.Bastion = bastion.ToObject(ctx)
+	}
+	d.Timeouts = timeouts.ToObject(ctx)
+
+	return &d, d.Validate(ctx)
+}
+
+func orEnv(value tftypes.String, key string) tftypes.String {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value
+	}
+	return envString(key)
+}
+
+func orEnvInt32(value tftypes.Int32, key string) (tftypes.Int32, error) {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value, nil
+	}
+	env, ok := os.LookupEnv(key)
+	if !ok || env == "" {
+		return tftypes.Int32Null(), nil
+	}
+	parsed, err := strconv.ParseInt(env, 10, 32)
+	if err != nil {
+		return value, fmt.Errorf("parsing %s: %s", key, err.Error())
+	}
+	return tftypes.Int32Value(int32(parsed)), nil
+}
+
+func (d DefaultAuth) Validate(ctx context.Context) error {
+	if !d.PrivateKey.IsNull() && !d.Password.IsNull() {
+		return fmt.Errorf("both password and private key were passed, only pass one")
+	}
+
+	if !d.Bastion.IsNull() {
+		var bastion Bastion
+		d.Bastion.As(ctx, &bastion, basetypes.ObjectAsOptions{})
+		if !bastion.PrivateKey.IsNull() && !bastion.Password.IsNull() {
+			return fmt.Errorf("both bastion password and private key were passed, only pass one")
+		}
+	}
+
+	var timeouts SshTimeouts
+	d.Timeouts.As(ctx, &timeouts, basetypes.ObjectAsOptions{})
+	for name, timeout := range map[string]tftypes.String{"connect": timeouts.Connect, "ready": timeouts.Ready} {
+		if timeout.IsNull() {
+			continue
+		}
+		if _, err := time.ParseDuration(timeout.ValueString()); err != nil {
+			return fmt.Errorf("%s timeout %s is not a duration: %s", name, timeout.ValueString(), err.Error())
+		}
+	}
+	return nil
+}
+
+// Whether nodes without credentials of their own can connect.
+func (d DefaultAuth) HasCredentials() bool {
+	return !d.PrivateKey.IsNull() || !d.Password.IsNull()
+}
+
+// Ssh client options for the bastion and timeouts. Bastion credentials
+// fall back to the ones of the node.
+func (d DefaultAuth) options(ctx context.Context, node NodeAuth) []ssh_client.Option {
+	var opts []ssh_client.Option
+
+	if !d.Bastion.IsNull() {
+		var bastion Bastion
+		d.Bastion.As(ctx, &bastion, basetypes.ObjectAsOptions{})
+		if bastion.User.IsNull() {
+			bastion.User = node.User
+		}
+		if bastion.PrivateKey.IsNull() && bastion.Password.IsNull() {
+			bastion.PrivateKey, bastion.Password = node.PrivateKey, node.Password
+		}
+		port := 22
+		if !bastion.Port.IsNull() {
+			port = int(bastion.Port.ValueInt32())
+		}
+		opts = append(opts, ssh_client.WithBastion(
+			bastion.Host.ValueString(),
+			port,
+			bastion.User.ValueString(),
+			bastion.PrivateKey.ValueString(),
+			bastion.Password.ValueString(),
+		))
+	}
+
+	var timeouts SshTimeouts
+	d.Timeouts.As(ctx, &timeouts, basetypes.ObjectAsOptions{})
+	if timeout, err := time.ParseDuration(timeouts.Connect.ValueString()); err == nil {
+		opts = append(opts, ssh_client.WithConnectTimeout(timeout))
+	}
+	if timeout, err := time.ParseDuration(timeouts.Ready.ValueString()); err == nil {
+		opts = append(opts, ssh_client.WithReadyTimeout(timeout))
+	}
+
+	return opts
+}
+
+func (DefaultAuth) Schema() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Optional: true,
+		MarkdownDescription: "Ssh settings used by the `auth` of every node that leaves them unset. " +
+			"Each falls back to an env var, noted below, when unset here",
+		Attributes: map[string]schema.Attribute{
+			"user": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Username of the target servers, `K3S_SSH_USER`",
+			},
+			"private_key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private ssh key used in place of a password, `K3S_SSH_PRIVATE_KEY`",
+			},
+			"password": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Password of the target servers, `K3S_SSH_PASSWORD`",
+			},
+			"port": schema.Int32Attribute{
+				Optional:            true,
+				MarkdownDescription: "Ssh port of the target servers, `K3S_SSH_PORT`. Defaults to 22",
+			},
+			"bastion": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Jump host every node is reached through",
+				Attributes: map[string]schema.Attribute{
+					"host": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Hostname of the bastion, `K3S_SSH_BASTION_HOST`",
+					},
+					"port": schema.Int32Attribute{
+						Optional:            true,
+						MarkdownDescription: "Ssh port of the bastion, `K3S_SSH_BASTION_PORT`. Defaults to 22",
+					},
+					"user": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Username on the bastion, `K3S_SSH_BASTION_USER`. Defaults to the user of the node",
+					},
+					"private_key": schema.StringAttribute{
+						Optional:            true,
+						Sensitive:           true,
+						MarkdownDescription: "Private ssh key of the bastion, `K3S_SSH_BASTION_PRIVATE_KEY`. Defaults to the credentials of the node",
+					},
+					"password": schema.StringAttribute{
+						Optional:            true,
+						Sensitive:           true,
+						MarkdownDescription: "Password of the bastion, `K3S_SSH_BASTION_PASSWORD`. Defaults to the credentials of the node",
+					},
+				},
+			},
+			"timeouts": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Ssh timeouts, as durations such as `30s`",
+				Attributes: map[string]schema.Attribute{
+					"connect": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Time to establish each connection, `K3S_SSH_CONNECT_TIMEOUT`",
+					},
+					"ready": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Time to wait for a node to accept connections, `K3S_SSH_READY_TIMEOUT`. Connecting is retried every 5s until this timeout or the resource timeouts, whichever is sooner, and at most 10 times when neither is set",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (DefaultAuth) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"user":        tftypes.StringType,
+		"private_key": tftypes.StringType,
+		"password":    tftypes.StringType,
+		"port":        tftypes.Int32Type,
+		"bastion":     tftypes.ObjectType{AttrTypes: Bastion{}.AttributeTypes()},
+		"timeouts":    tftypes.ObjectType{AttrTypes: SshTimeouts{}.AttributeTypes()},
+	}
+}
+
+func (b *Bastion) ToObject(ctx context.Context) basetypes.ObjectValue {
+	return ToObject(ctx, b)
+}
+
+func (Bastion) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"host":        tftypes.StringType,
+		"port":        tftypes.Int32Type,
+		"user":        tftypes.StringType,
+		"private_key": tftypes.StringType,
+		"password":    tftypes.StringType,
+	}
+}
+
+func (t *SshTimeouts) ToObject(ctx context.Context) basetypes.ObjectValue {
+	return ToObject(ctx, t)
+}
+
+func (SshTimeouts) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"connect": tftypes.StringType,
+		"ready":   tftypes.StringType,
+	}
+}
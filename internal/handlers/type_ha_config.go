@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -17,7 +18,11 @@ import (
 type HaConfig struct {
 	ClusterInit types.Bool   `tfsdk:"cluster_init"`
 	Token       types.String `tfsdk:"token"`
+	AgentToken  types.String `tfsdk:"agent_token"`
 	Server      types.String `tfsdk:"server"`
+	// Write-only variant of the token, never stored in state
+	TokenWo        types.String `tfsdk:"token_wo"`
+	TokenWoVersion types.Int64  `tfsdk:"token_wo_version"`
 }
 
 // Schema implements K3sType.
@@ -44,12 +49,26 @@ func (m HaConfig) Schema() schema.Attribute {
 				Sensitive:           true,
 				MarkdownDescription: "Server token used for joining nodes to the cluster",
 			},
+			"token_wo": schema.StringAttribute{
+				Optional:  true,
+				Sensitive: true,
+				WriteOnly: true,
+				MarkdownDescription: "Write-only variant of `token`, never stored in state. Only one of `token` or `token_wo` can be passed. " +
+					"Changing `token_wo_version` replaces the server, as it has to join with the new token",
+			},
+			"token_wo_version": WriteOnlyVersionSchema("token_wo", int64planmodifier.RequiresReplace()),
+			"agent_token": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Token only usable for joining agents to the cluster",
+			},
 		},
 	}
 }
 
 func (h HaConfig) configureServer(server k3s.ServerHaMode) {
-	server.AddHA(h.ClusterInit.ValueBool(), h.Token.ValueString(), h.Server.ValueString())
+	server.AddHA(h.ClusterInit.ValueBool(), h.token(), h.Server.ValueString())
+	server.AddAgentToken(h.AgentToken.ValueString())
 }
 
 func NewHaConfig(ctx context.Context, t basetypes.ObjectValue) HaConfig {
@@ -64,18 +83,33 @@ func (m *HaConfig) ToObject(ctx context.Context) basetypes.ObjectValue {
 
 func (m HaConfig) AttributeTypes() map[string]attr.Type {
 	return map[string]attr.Type{
-		"cluster_init": types.BoolType,
-		"token":        types.StringType,
-		"server":       types.StringType,
+		"cluster_init":     types.BoolType,
+		"token":            types.StringType,
+		"agent_token":      types.StringType,
+		"server":           types.StringType,
+		"token_wo":         types.StringType,
+		"token_wo_version": types.Int64Type,
 	}
 }
 
 func (h HaConfig) Validate() error {
-	if !h.ClusterInit.ValueBool() && (h.Token.IsNull() || h.Server.IsNull()) {
+	noToken := h.Token.IsNull() && h.TokenWo.IsNull()
+	if !h.ClusterInit.ValueBool() && (noToken || h.Server.IsNull()) {
 		return fmt.Errorf("when not in cluster-init, token and server must be passed")
 	}
-	if h.ClusterInit.ValueBool() && (!h.Token.IsNull() || !h.Server.IsNull()) {
+	if h.ClusterInit.ValueBool() && (!noToken || !h.Server.IsNull()) {
 		return fmt.Errorf("when in cluster-init, token and server must not be passed")
 	}
+	if !h.Token.IsNull() && !h.TokenWo.IsNull() {
+		return fmt.Errorf("both token and token_wo were passed, only pass one")
+	}
 	return nil
 }
+
+// The token passed, either as is or write-only.
+func (h HaConfig) token() string {
+	if !h.TokenWo.IsNull() {
+		return h.TokenWo.ValueString()
+	}
+	return h.Token.ValueString()
+}
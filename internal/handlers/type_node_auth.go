@@ -3,10 +3,14 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
@@ -22,6 +26,9 @@ type NodeAuth struct {
 	PrivateKey tftypes.String `tfsdk:"private_key"`
 	Password   tftypes.String `tfsdk:"password"`
 	User       tftypes.String `tfsdk:"user"`
+
+	defaults  *DefaultAuth
+	writeOnly *AuthWriteOnly
 }
 
 func DefaultNodeAuth() basetypes.ObjectValue {
@@ -35,70 +42,205 @@ func NewNodeAuth(ctx context.Context, t basetypes.ObjectValue) NodeAuth {
 	return na
 }
 
-func (n NodeAuth) Validate() error {
-	if n.PrivateKey.IsNull() && n.Password.IsNull() {
-		return fmt.Errorf("neither password nor private key was passed")
+// Builds the auth of an imported node from an id of `<kind>,host[:port]`.
+// Credentials can't be part of the id, so they come from the provider's
+// default_auth, or its K3S_SSH_USER, K3S_SSH_PRIVATE_KEY and K3S_SSH_PASSWORD env vars.
+func NewImportNodeAuth(id string, kind string, defaults *DefaultAuth) (NodeAuth, error) {
+	na := NodeAuth{
+		Port:       tftypes.Int32Null(),
+		User:       tftypes.StringNull(),
+		PrivateKey: tftypes.StringNull(),
+		Password:   tftypes.StringNull(),
+		defaults:   defaults,
+	}
+
+	prefix, address, found := strings.Cut(id, ",")
+	if !found || prefix != kind || address == "" {
+		return na, fmt.Errorf("expected an import id of %s,host[:port], got %s", kind, id)
+	}
+
+	host := address
+	if h, p, err := net.SplitHostPort(address); err == nil {
+		port, err := strconv.ParseInt(p, 10, 32)
+		if err != nil {
+			return na, fmt.Errorf("parsing port of %s: %s", address, err.Error())
+		}
+		host = h
+		na.Port = tftypes.Int32Value(int32(port))
+	}
+	na.Host = tftypes.StringValue(host)
+	na.Port = na.resolve().Port
+
+	if err := na.resolve().validateCredentials(); err != nil {
+		return na, fmt.Errorf("%s, set them in the provider's default_auth, or K3S_SSH_PRIVATE_KEY or K3S_SSH_PASSWORD, to import", err.Error())
 	}
+	return na, nil
+}
+
+func envString(key string) tftypes.String {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return tftypes.StringValue(value)
+	}
+	return tftypes.StringNull()
+}
 
+// Validates the auth as configured. Credentials may be left out, in
+// which case they come from the provider's default_auth.
+func (n NodeAuth) Validate() error {
 	if !n.PrivateKey.IsNull() && !n.Password.IsNull() {
 		return fmt.Errorf("both password and private key were passed, only pass one")
 	}
+	if n.writeOnly != nil {
+		if !n.PrivateKey.IsNull() || !n.Password.IsNull() {
+			return fmt.Errorf("credentials were passed in both auth and auth_wo, only pass one")
+		}
+		return n.writeOnly.Validate()
+	}
 	return nil
 }
 
-func (auth NodeAuth) SshClient(ctx context.Context) (ssh_client.SSHClient, error) {
-	port := 22
-	if int(auth.Port.ValueInt32()) != 0 {
-		port = int(auth.Port.ValueInt32())
+func (n NodeAuth) validateCredentials() error {
+	if n.PrivateKey.IsNull() && n.Password.IsNull() {
+		return fmt.Errorf("neither password nor private key was passed")
+	}
+	return n.Validate()
+}
+
+// Falls back to the provider's default_auth for unset fields.
+func (n NodeAuth) WithDefaults(defaults *DefaultAuth) NodeAuth {
+	n.defaults = defaults
+	return n
+}
+
+// Takes the credentials from auth_wo. They are kept apart from the
+// attributes so they never make it into state.
+func (n NodeAuth) WithWriteOnly(ctx context.Context, t basetypes.ObjectValue) NodeAuth {
+	if t.IsNull() || t.IsUnknown() {
+		return n
+	}
+	wo := NewAuthWriteOnly(ctx, t)
+	n.writeOnly = &wo
+	return n
+}
+
+// The auth with unset fields taken from auth_wo, then the defaults. Credentials
+// are only taken as a whole, so a node's password never pairs with a default key.
+func (n NodeAuth) resolve() NodeAuth {
+	if n.writeOnly != nil && n.PrivateKey.IsNull() && n.Password.IsNull() {
+		n.PrivateKey, n.Password = n.writeOnly.PrivateKey, n.writeOnly.Password
+	}
+	n.writeOnly = nil
+	if n.defaults != nil {
+		if n.User.IsNull() {
+			n.User = n.defaults.User
+		}
+		if n.Port.IsNull() || n.Port.IsUnknown() {
+			n.Port = n.defaults.Port
+		}
+		if n.PrivateKey.IsNull() && n.Password.IsNull() {
+			n.PrivateKey, n.Password = n.defaults.PrivateKey, n.defaults.Password
+		}
+	}
+	if n.Port.IsNull() || n.Port.IsUnknown() {
+		n.Port = tftypes.Int32Value(22)
+	}
+	return n
+}
+
+// The auth as kept in state, with the port it connects on. Only the port
+// is resolved, credentials taken from the defaults never make it into state.
+func (n NodeAuth) StateObject(ctx context.Context) basetypes.ObjectValue {
+	n.Port = n.resolve().Port
+	return n.ToObject(ctx)
+}
+
+func (n NodeAuth) SshClient(ctx context.Context) (ssh_client.SSHClient, error) {
+	auth := n.resolve()
+	if err := auth.validateCredentials(); err != nil {
+		return nil, err
+	}
+
+	var opts []ssh_client.Option
+	if auth.defaults != nil {
+		opts = auth.defaults.options(ctx, auth)
 	}
 	return ssh_client.NewSSHClient(
 		ctx, auth.Host.ValueString(),
-		port,
+		int(auth.Port.ValueInt32()),
 		auth.User.ValueString(),
 		auth.PrivateKey.ValueString(),
 		auth.Password.ValueString(),
+		opts...,
 	)
 
 }
 
+// Same credentials against a different host.
+func (n NodeAuth) ForHost(host string) K3sTypeSSH {
+	n.Host = tftypes.StringValue(host)
+	return n
+}
+
 func (n *NodeAuth) ToObject(ctx context.Context) basetypes.ObjectValue {
 	return ToObject(ctx, n)
 }
 
-func (NodeAuth) Schema() schema.Attribute {
+func (n NodeAuth) Schema() schema.Attribute {
+	attributes := n.attributes()
+	attributes["host"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Hostname of the target server",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
 	return schema.SingleNestedAttribute{
 		Required:    true,
 		Description: "Auth configuration for the node",
 		PlanModifiers: []planmodifier.Object{
 			objectplanmodifier.UseStateForUnknown(),
 		},
-		Attributes: map[string]schema.Attribute{
-			"private_key": schema.StringAttribute{
-				Optional:            true,
-				Sensitive:           true,
-				MarkdownDescription: "Private ssh key value to be used in place of a password",
-			},
-			"password": schema.StringAttribute{
-				Optional:            true,
-				Sensitive:           true,
-				MarkdownDescription: "Username of the target server",
-			},
-			"user": schema.StringAttribute{
-				Optional:            true,
-				MarkdownDescription: "Username of the target server",
-			},
-			"host": schema.StringAttribute{
-				Optional:            true,
-				MarkdownDescription: "Hostname of the target server",
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
-			"port": schema.Int32Attribute{
-				Optional:            true,
-				Computed:            true,
-				Default:             int32default.StaticInt32(22),
-				MarkdownDescription: "Override default SSH port (22)",
+		Attributes: attributes,
+	}
+}
+
+// Auth of a node within a k3s_cluster, where a new host replaces the node
+// rather than the whole resource.
+func (n NodeAuth) ClusterNodeSchema() schema.Attribute {
+	attributes := n.attributes()
+	attributes["host"] = schema.StringAttribute{
+		Required:            true,
+		MarkdownDescription: "Hostname of the target server",
+	}
+	return schema.SingleNestedAttribute{
+		Required:    true,
+		Description: "Auth configuration for the node",
+		Attributes:  attributes,
+	}
+}
+
+func (NodeAuth) attributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"private_key": schema.StringAttribute{
+			Optional:            true,
+			Sensitive:           true,
+			MarkdownDescription: "Private ssh key value to be used in place of a password, defaults to the provider's `default_auth`",
+		},
+		"password": schema.StringAttribute{
+			Optional:            true,
+			Sensitive:           true,
+			MarkdownDescription: "Password of the target server, defaults to the provider's `default_auth`",
+		},
+		"user": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "Username of the target server, defaults to the provider's `default_auth`",
+		},
+		"port": schema.Int32Attribute{
+			Optional:            true,
+			Computed:            true,
+			MarkdownDescription: "Override default SSH port, defaults to the provider's `default_auth`, then 22",
+			PlanModifiers: []planmodifier.Int32{
+				int32planmodifier.UseStateForUnknown(),
 			},
 		},
 	}
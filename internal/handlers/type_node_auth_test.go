@@ -0,0 +1,153 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+func TestNewImportNodeAuth(t *testing.T) {
+	t.Setenv("K3S_SSH_USER", "ubuntu")
+	t.Setenv("K3S_SSH_PRIVATE_KEY", "abc123")
+	t.Setenv("K3S_SSH_PASSWORD", "")
+
+	defaults, err := handlers.NewDefaultAuth(t.Context(), types.ObjectNull(handlers.DefaultAuth{}.AttributeTypes()))
+	if err != nil {
+		t.Fatalf("Default auth from env shouldn't raise, got %s", err.Error())
+	}
+
+	t.Run("Host", func(t *testing.T) {
+		auth, err := handlers.NewImportNodeAuth("server,192.168.1.2", "server", defaults)
+		if err != nil {
+			t.Fatalf("Good id shouldn't raise, got %s", err.Error())
+		}
+		if !auth.Host.Equal(types.StringValue("192.168.1.2")) || !auth.Port.Equal(types.Int32Value(22)) {
+			t.Errorf("Expected 192.168.1.2 on the default port, got %s:%s", auth.Host, auth.Port)
+		}
+		if !auth.User.IsNull() || !auth.PrivateKey.IsNull() || !auth.Password.IsNull() {
+			t.Errorf("Expected credentials to be left to the defaults, got %s %s %s", auth.User, auth.PrivateKey, auth.Password)
+		}
+	})
+
+	t.Run("Host and port", func(t *testing.T) {
+		auth, err := handlers.NewImportNodeAuth("agent,node-1.example.com:2222", "agent", defaults)
+		if err != nil {
+			t.Fatalf("Good id shouldn't raise, got %s", err.Error())
+		}
+		if !auth.Host.Equal(types.StringValue("node-1.example.com")) || !auth.Port.Equal(types.Int32Value(2222)) {
+			t.Errorf("Expected node-1.example.com:2222, got %s:%s", auth.Host, auth.Port)
+		}
+	})
+
+	for _, id := range []string{"192.168.1.2", "agent,192.168.1.2", "server,", "server,192.168.1.2:ssh"} {
+		if _, err := handlers.NewImportNodeAuth(id, "server", defaults); err == nil {
+			t.Errorf("Bad id %s should raise, got nil", id)
+		}
+	}
+
+	t.Run("No credentials", func(t *testing.T) {
+		if _, err := handlers.NewImportNodeAuth("server,192.168.1.2", "server", nil); err == nil {
+			t.Errorf("Missing credentials should raise, got nil")
+		}
+	})
+}
+
+func TestDefaultAuth(t *testing.T) {
+	t.Setenv("K3S_SSH_USER", "ubuntu")
+	t.Setenv("K3S_SSH_PRIVATE_KEY", "")
+	t.Setenv("K3S_SSH_PASSWORD", "hunter2")
+	t.Setenv("K3S_SSH_PORT", "2222")
+	t.Setenv("K3S_SSH_CONNECT_TIMEOUT", "")
+
+	t.Run("Env", func(t *testing.T) {
+		defaults, err := handlers.NewDefaultAuth(t.Context(), types.ObjectNull(handlers.DefaultAuth{}.AttributeTypes()))
+		if err != nil {
+			t.Fatalf("Default auth from env shouldn't raise, got %s", err.Error())
+		}
+		if !defaults.User.Equal(types.StringValue("ubuntu")) || !defaults.Password.Equal(types.StringValue("hunter2")) || !defaults.Port.Equal(types.Int32Value(2222)) {
+			t.Errorf("Expected settings from env, got %s %s %s", defaults.User, defaults.Password, defaults.Port)
+		}
+		if !defaults.HasCredentials() {
+			t.Errorf("Expected the password from env to be usable")
+		}
+	})
+
+	t.Run("Provider block wins", func(t *testing.T) {
+		block, _ := types.ObjectValue(handlers.DefaultAuth{}.AttributeTypes(), map[string]attr.Value{
+			"user":        types.StringValue("admin"),
+			"private_key": types.StringNull(),
+			"password":    types.StringNull(),
+			"port":        types.Int32Null(),
+			"bastion":     types.ObjectNull(handlers.Bastion{}.AttributeTypes()),
+			"timeouts":    types.ObjectNull(handlers.SshTimeouts{}.AttributeTypes()),
+		})
+		defaults, err := handlers.NewDefaultAuth(t.Context(), block)
+		if err != nil {
+			t.Fatalf("Good default auth shouldn't raise, got %s", err.Error())
+		}
+		if !defaults.User.Equal(types.StringValue("admin")) {
+			t.Errorf("Expected user from the provider block, got %s", defaults.User)
+		}
+
+		t.Setenv("K3S_SSH_PASSWORD", "")
+		defaults, err = handlers.NewDefaultAuth(t.Context(), block)
+		if err != nil {
+			t.Fatalf("Good default auth shouldn't raise, got %s", err.Error())
+		}
+		if defaults.HasCredentials() {
+			t.Errorf("Expected no credentials without a key or password")
+		}
+	})
+
+	t.Run("Bad env", func(t *testing.T) {
+		t.Setenv("K3S_SSH_CONNECT_TIMEOUT", "soon")
+		if _, err := handlers.NewDefaultAuth(t.Context(), types.ObjectNull(handlers.DefaultAuth{}.AttributeTypes())); err == nil {
+			t.Errorf("Bad connect timeout should raise, got nil")
+		}
+	})
+}
+
+func TestNodeAuthStateObject(t *testing.T) {
+	t.Setenv("K3S_SSH_PASSWORD", "")
+	t.Setenv("K3S_SSH_PRIVATE_KEY", "abc123")
+
+	auth := func(port types.Int32) handlers.NodeAuth {
+		obj, _ := types.ObjectValue(handlers.NodeAuth{}.AttributeTypes(), map[string]attr.Value{
+			"host":        types.StringValue("192.168.1.2"),
+			"port":        port,
+			"private_key": types.StringNull(),
+			"password":    types.StringNull(),
+			"user":        types.StringNull(),
+		})
+		return handlers.NewNodeAuth(t.Context(), obj)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		port     types.Int32
+		env      string
+		expected int32
+	}{
+		{"Unset", types.Int32Unknown(), "", 22},
+		{"Default auth", types.Int32Unknown(), "2222", 2222},
+		{"Node", types.Int32Value(2200), "2222", 2200},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("K3S_SSH_PORT", tc.env)
+			defaults, err := handlers.NewDefaultAuth(t.Context(), types.ObjectNull(handlers.DefaultAuth{}.AttributeTypes()))
+			if err != nil {
+				t.Fatalf("Default auth from env shouldn't raise, got %s", err.Error())
+			}
+
+			state := handlers.NewNodeAuth(t.Context(), auth(tc.port).WithDefaults(defaults).StateObject(t.Context()))
+			if !state.Port.Equal(types.Int32Value(tc.expected)) {
+				t.Errorf("Expected port %d, got %s", tc.expected, state.Port)
+			}
+			if !state.PrivateKey.IsNull() {
+				t.Errorf("Default credentials shouldn't make it into state")
+			}
+		})
+	}
+}
@@ -20,6 +20,9 @@ type OidcConfig struct {
 	SigningKey   types.String `tfsdk:"signing_key"`
 	Issuer       types.String `tfsdk:"issuer"`
 	JWKSKeys     types.String `tfsdk:"jwks_keys"`
+	// Write-only variant of the signing key, never stored in state
+	SigningKeyWo        types.String `tfsdk:"signing_key_wo"`
+	SigningKeyWoVersion types.Int64  `tfsdk:"signing_key_wo_version"`
 }
 
 func (m OidcConfig) Schema() schema.Attribute {
@@ -38,10 +41,17 @@ func (m OidcConfig) Schema() schema.Attribute {
 				MarkdownDescription: "Public signing key",
 			},
 			"signing_key": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private signing key. Only one of `signing_key` or `signing_key_wo` can be passed",
+			},
+			"signing_key_wo": schema.StringAttribute{
+				Optional:            true,
 				Sensitive:           true,
-				MarkdownDescription: "Private signing key",
+				WriteOnly:           true,
+				MarkdownDescription: "Write-only variant of `signing_key`, never stored in state",
 			},
+			"signing_key_wo_version": WriteOnlyVersionSchema("signing_key_wo"),
 			"issuer": schema.StringAttribute{
 				Required:            true,
 				Sensitive:           true,
@@ -60,9 +70,32 @@ func (m OidcConfig) Schema() schema.Attribute {
 }
 
 func (n OidcConfig) Validate() error {
+	if n.SigningKey.IsNull() && n.SigningKeyWo.IsNull() {
+		return fmt.Errorf("one of signing_key or signing_key_wo must be passed")
+	}
+	if !n.SigningKey.IsNull() && !n.SigningKeyWo.IsNull() {
+		return fmt.Errorf("both signing_key and signing_key_wo were passed, only pass one")
+	}
 	return nil
 }
 
+// Whether the inputs differ. The computed jwks_keys are left out, and
+// signing_key_wo, which is never in state, is compared by its version.
+func (n OidcConfig) changed(o OidcConfig) bool {
+	return !n.Audience.Equal(o.Audience) ||
+		!n.SigningPKCS8.Equal(o.SigningPKCS8) ||
+		!n.SigningKey.Equal(o.SigningKey) ||
+		!n.Issuer.Equal(o.Issuer) ||
+		!n.SigningKeyWoVersion.Equal(o.SigningKeyWoVersion)
+}
+
+func oidcChanged(ctx context.Context, state, config basetypes.ObjectValue) bool {
+	if state.IsNull() || config.IsNull() {
+		return state.IsNull() != config.IsNull()
+	}
+	return NewOidcConfig(ctx, state).changed(NewOidcConfig(ctx, config))
+}
+
 func (m *OidcConfig) ToObject(ctx context.Context) basetypes.ObjectValue {
 	return ToObject(ctx, m)
 }
@@ -75,11 +108,13 @@ func NewOidcConfig(ctx context.Context, t basetypes.ObjectValue) OidcConfig {
 
 func (m OidcConfig) AttributeTypes() map[string]attr.Type {
 	return map[string]attr.Type{
-		"audience":    types.StringType,
-		"pkcs8":       types.StringType,
-		"signing_key": types.StringType,
-		"issuer":      types.StringType,
-		"jwks_keys":   types.StringType,
+		"audience":               types.StringType,
+		"pkcs8":                  types.StringType,
+		"signing_key":            types.StringType,
+		"issuer":                 types.StringType,
+		"jwks_keys":              types.StringType,
+		"signing_key_wo":         types.StringType,
+		"signing_key_wo_version": types.Int64Type,
 	}
 }
 
@@ -88,7 +123,7 @@ func (m OidcConfig) configureServer(server k3s.ServerOidc) {
 		m.Audience.ValueString(),
 		m.Issuer.ValueString(),
 		m.SigningPKCS8.ValueString(),
-		m.SigningKey.ValueString(),
+		m.signingKey(),
 	)
 }
 
@@ -101,3 +136,11 @@ func (m *OidcConfig) setJwks(client ssh_client.SSHRun) error {
 	m.JWKSKeys = types.StringValue(res[0])
 	return nil
 }
+
+// The signing key passed, either as is or write-only.
+func (m OidcConfig) signingKey() string {
+	if !m.SigningKeyWo.IsNull() {
+		return m.SigningKeyWo.ValueString()
+	}
+	return m.SigningKey.ValueString()
+}
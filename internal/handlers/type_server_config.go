@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Components that can be passed to `disable`.
+var DisableableComponents = []string{"coredns", "servicelb", "traefik", "local-storage", "metrics-server", "runtimes"}
+
+// Values accepted by `flannel-backend`.
+var FlannelBackends = []string{"none", "vxlan", "host-gw", "wireguard-native"}
+
+type ServerConfig struct {
+	NodeName                  types.String `tfsdk:"node_name"`
+	NodeIp                    types.String `tfsdk:"node_ip"`
+	NodeExternalIp            types.String `tfsdk:"node_external_ip"`
+	NodeLabels                types.List   `tfsdk:"node_labels"`
+	NodeTaints                types.List   `tfsdk:"node_taints"`
+	TlsSan                    types.List   `tfsdk:"tls_san"`
+	Disable                   types.List   `tfsdk:"disable"`
+	ClusterCidr               types.String `tfsdk:"cluster_cidr"`
+	ServiceCidr               types.String `tfsdk:"service_cidr"`
+	ClusterDns                types.String `tfsdk:"cluster_dns"`
+	FlannelBackend            types.String `tfsdk:"flannel_backend"`
+	WriteKubeconfigMode       types.String `tfsdk:"write_kubeconfig_mode"`
+	KubeletArgs               types.List   `tfsdk:"kubelet_args"`
+	KubeApiserverArgs         types.List   `tfsdk:"kube_apiserver_args"`
+	KubeControllerManagerArgs types.List   `tfsdk:"kube_controller_manager_args"`
+	KubeSchedulerArgs         types.List   `tfsdk:"kube_scheduler_args"`
+	KubeProxyArgs             types.List   `tfsdk:"kube_proxy_args"`
+}
+
+func (m ServerConfig) Schema() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Optional:    true,
+		Description: "Common k3s server flags, merged on top of `config`. Flags set here take precedence over the same keys in `config`",
+		Attributes: map[string]schema.Attribute{
+			"node_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Node name, `node-name`",
+			},
+			"node_ip": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "IP address to advertise for the node, `node-ip`",
+			},
+			"node_external_ip": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "External IP address to advertise for the node, `node-external-ip`",
+			},
+			"node_labels": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Labels to register the node with, e.g. `role=control-plane`, `node-label`",
+			},
+			"node_taints": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Taints to register the node with, e.g. `key=value:NoSchedule`, `node-taint`",
+			},
+			"tls_san": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional hostnames or IPs as Subject Alternative Names on the server certificate, `tls-san`",
+			},
+			"disable": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Packaged components to not deploy, e.g. `traefik` or `servicelb`, `disable`",
+			},
+			"cluster_cidr": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "IPv4/IPv6 network CIDRs to use for pod IPs, `cluster-cidr`",
+			},
+			"service_cidr": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "IPv4/IPv6 network CIDRs to use for service IPs, `service-cidr`",
+			},
+			"cluster_dns": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "IPv4 Cluster IP for the coredns service, `cluster-dns`",
+			},
+			"flannel_backend": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Flannel backend, one of `none`, `vxlan`, `host-gw` or `wireguard-native`, `flannel-backend`",
+			},
+			"write_kubeconfig_mode": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "File mode of the admin kubeconfig on the node, e.g. `0644`, `write-kubeconfig-mode`",
+			},
+			"kubelet_args": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Customized flags for the kubelet process, `kubelet-arg`",
+			},
+			"kube_apiserver_args": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Customized flags for the kube-apiserver process, `kube-apiserver-arg`",
+			},
+			"kube_controller_manager_args": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Customized flags for the kube-controller-manager process, `kube-controller-manager-arg`",
+			},
+			"kube_scheduler_args": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Customized flags for the kube-scheduler process, `kube-scheduler-arg`",
+			},
+			"kube_proxy_args": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Customized flags for the kube-proxy process, `kube-proxy-arg`",
+			},
+		},
+	}
+}
+
+func NewServerConfig(ctx context.Context, t basetypes.ObjectValue) ServerConfig {
+	var na ServerConfig
+	t.As(ctx, &na, basetypes.ObjectAsOptions{})
+	return na
+}
+
+func (m *ServerConfig) ToObject(ctx context.Context) basetypes.ObjectValue {
+	return ToObject(ctx, m)
+}
+
+func (m ServerConfig) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"node_name":                    types.StringType,
+		"node_ip":                      types.StringType,
+		"node_external_ip":             types.StringType,
+		"node_labels":                  types.ListType{ElemType: types.StringType},
+		"node_taints":                  types.ListType{ElemType: types.StringType},
+		"tls_san":                      types.ListType{ElemType: types.StringType},
+		"disable":                      types.ListType{ElemType: types.StringType},
+		"cluster_cidr":                 types.StringType,
+		"service_cidr":                 types.StringType,
+		"cluster_dns":                  types.StringType,
+		"flannel_backend":              types.StringType,
+		"write_kubeconfig_mode":        types.StringType,
+		"kubelet_args":                 types.ListType{ElemType: types.StringType},
+		"kube_apiserver_args":          types.ListType{ElemType: types.StringType},
+		"kube_controller_manager_args": types.ListType{ElemType: types.StringType},
+		"kube_scheduler_args":          types.ListType{ElemType: types.StringType},
+		"kube_proxy_args":              types.ListType{ElemType: types.StringType},
+	}
+}
+
+func (m ServerConfig) Validate(ctx context.Context) error {
+	var disable []string
+	m.Disable.ElementsAs(ctx, &disable, false)
+	for _, component := range disable {
+		if !slices.Contains(DisableableComponents, component) {
+			return fmt.Errorf("unknown component %s to disable, expected one of %v", component, DisableableComponents)
+		}
+	}
+
+	if !m.FlannelBackend.IsNull() && !m.FlannelBackend.IsUnknown() && !slices.Contains(FlannelBackends, m.FlannelBackend.ValueString()) {
+		return fmt.Errorf("unknown flannel backend %s, expected one of %v", m.FlannelBackend.ValueString(), FlannelBackends)
+	}
+	return nil
+}
+
+// The k3s config entries of the set flags.
+func (m ServerConfig) config(ctx context.Context) map[any]any {
+	return configEntries(ctx, map[string]attr.Value{
+		"node-name":                   m.NodeName,
+		"node-ip":                     m.NodeIp,
+		"node-external-ip":            m.NodeExternalIp,
+		"node-label":                  m.NodeLabels,
+		"node-taint":                  m.NodeTaints,
+		"tls-san":                     m.TlsSan,
+		"disable":                     m.Disable,
+		"cluster-cidr":                m.ClusterCidr,
+		"service-cidr":                m.ServiceCidr,
+		"cluster-dns":                 m.ClusterDns,
+		"flannel-backend":             m.FlannelBackend,
+		"write-kubeconfig-mode":       m.WriteKubeconfigMode,
+		"kubelet-arg":                 m.KubeletArgs,
+		"kube-apiserver-arg":          m.KubeApiserverArgs,
+		"kube-controller-manager-arg": m.KubeControllerManagerArgs,
+		"kube-scheduler-arg":          m.KubeSchedulerArgs,
+		"kube-proxy-arg":              m.KubeProxyArgs,
+	})
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+func stringList(t *testing.T, values ...string) types.List {
+	list, _ := types.ListValueFrom(t.Context(), types.StringType, values)
+	return list
+}
+
+func TestServerConfigMerge(t *testing.T) {
+	t.Parallel()
+
+	config := handlers.ServerConfig{
+		NodeLabels:     types.ListNull(types.StringType),
+		NodeTaints:     types.ListNull(types.StringType),
+		TlsSan:         stringList(t, "k3s.example.com"),
+		Disable:        stringList(t, "traefik"),
+		FlannelBackend: types.StringValue("wireguard-native"),
+	}
+	for _, list := range []*types.List{&config.KubeletArgs, &config.KubeApiserverArgs, &config.KubeControllerManagerArgs, &config.KubeSchedulerArgs, &config.KubeProxyArgs} {
+		*list = types.ListNull(types.StringType)
+	}
+
+	data := handlers.ServerClientModel{
+		K3sConfig:    handlers.NewYamlValue("tls-san: [10.0.0.1]\nnode-label: [role=server]\n"),
+		ServerConfig: config.ToObject(t.Context()),
+	}
+	server, err := data.ToServer(t.Context())
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+
+	for key, expected := range map[string]string{
+		"tls-san":         "[k3s.example.com]",
+		"disable":         "[traefik]",
+		"flannel-backend": "wireguard-native",
+		"node-label":      "[role=server]",
+	} {
+		if got := fmt.Sprint(server.Config()[key]); got != expected {
+			t.Errorf("Expected %s of %s, got %s", key, expected, got)
+		}
+	}
+	if _, ok := server.Config()["cluster-cidr"]; ok {
+		t.Errorf("Unset flags shouldn't be in the config")
+	}
+}
+
+func TestServerConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	good := handlers.ServerConfig{Disable: stringList(t, "traefik", "servicelb"), FlannelBackend: types.StringValue("vxlan")}
+	if err := good.Validate(t.Context()); err != nil {
+		t.Errorf("Good config shouldn't raise, got %s", err.Error())
+	}
+
+	badDisable := handlers.ServerConfig{Disable: stringList(t, "traefik-ingress"), FlannelBackend: types.StringNull()}
+	if err := badDisable.Validate(t.Context()); err == nil {
+		t.Errorf("Unknown component should raise, got nil")
+	}
+
+	badBackend := handlers.ServerConfig{Disable: types.ListNull(types.StringType), FlannelBackend: types.StringValue("ipsec")}
+	if err := badBackend.Validate(t.Context()); err == nil {
+		t.Errorf("Unknown flannel backend should raise, got nil")
+	}
+}
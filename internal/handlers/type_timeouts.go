@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	tftypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Defaults of the `timeouts` block of k3s nodes. Installs pull the k3s binary
+// and images on the node, so create and update are given the most time.
+const (
+	DefaultCreateTimeout = 20 * time.Minute
+	DefaultReadTimeout   = 5 * time.Minute
+	DefaultUpdateTimeout = 20 * time.Minute
+	DefaultDeleteTimeout = 10 * time.Minute
+)
+
+// The `timeouts` block of k3s nodes.
+func TimeoutsSchema(ctx context.Context) schema.Block {
+	return timeouts.Block(ctx, timeouts.Opts{
+		Create:            true,
+		Read:              true,
+		Update:            true,
+		Delete:            true,
+		CreateDescription: "Time to wait for the node to be installed, as a duration such as `30m`. Defaults to `20m`",
+		ReadDescription:   "Time to wait for the node to be read, as a duration such as `30m`. Defaults to `5m`",
+		UpdateDescription: "Time to wait for the node config to be updated, as a duration such as `30m`. Defaults to `20m`",
+		DeleteDescription: "Time to wait for the node to be uninstalled, as a duration such as `30m`. Defaults to `10m`",
+	})
+}
+
+// An unset `timeouts` block, for state that isn't built from a plan.
+func DefaultTimeouts() timeouts.Value {
+	return timeouts.Value{
+		Object: tftypes.ObjectNull(map[string]attr.Type{
+			"create": tftypes.StringType,
+			"read":   tftypes.StringType,
+			"update": tftypes.StringType,
+			"delete": tftypes.StringType,
+		}),
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+type TokenRotation struct {
+	NewToken types.String `tfsdk:"new_token"`
+	Servers  types.List   `tfsdk:"servers"`
+}
+
+func (m TokenRotation) Schema() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Optional:    true,
+		Description: "Rotate the server token of the cluster instead of creating a bootstrap token",
+		PlanModifiers: []planmodifier.Object{
+			objectplanmodifier.RequiresReplaceIf(
+				func(ctx context.Context, req planmodifier.ObjectRequest, resp *objectplanmodifier.RequiresReplaceIfFuncResponse) {
+					resp.RequiresReplace = req.StateValue.IsNull() != req.PlanValue.IsNull()
+				},
+				"Switching between a bootstrap token and a rotation requires replacement",
+				"Switching between a bootstrap token and a rotation requires replacement",
+			),
+		},
+		Attributes: map[string]schema.Attribute{
+			"new_token": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Token to rotate to, a random token is generated if not passed",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"servers": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Hosts of the remaining servers in the cluster, restarted with the new token using the same `auth` credentials",
+			},
+		},
+	}
+}
+
+func NewTokenRotation(ctx context.Context, t basetypes.ObjectValue) TokenRotation {
+	var na TokenRotation
+	t.As(ctx, &na, basetypes.ObjectAsOptions{})
+	return na
+}
+
+func (m *TokenRotation) ToObject(ctx context.Context) basetypes.ObjectValue {
+	return ToObject(ctx, m)
+}
+
+func (m TokenRotation) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"new_token": types.StringType,
+		"servers":   types.ListType{ElemType: types.StringType},
+	}
+}
+
+func (m TokenRotation) servers(ctx context.Context) []string {
+	var servers []string
+	m.Servers.ElementsAs(ctx, &servers, false)
+	return servers
+}
+
+// Rotates the cluster token on the node behind `client`, then restarts every
+// server with the new token.
+func (m *TokenRotation) rotate(ctx context.Context, client ssh_client.SSHClient, auth TTokenSSH, server k3s.ServerTokens) error {
+	if m.NewToken.IsNull() || m.NewToken.IsUnknown() {
+		token, err := generateToken()
+		if err != nil {
+			return fmt.Errorf("generating token: %s", err.Error())
+		}
+		m.NewToken = types.StringValue(token)
+	}
+
+	if err := server.RotateToken(client, m.NewToken.ValueString()); err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "k3s server token rotated")
+
+	if err := server.UpdateToken(client, m.NewToken.ValueString()); err != nil {
+		return fmt.Errorf("restarting %s with new token: %s", client.HostnameOrIpAddress(), err.Error())
+	}
+
+	return m.updateServers(ctx, m.servers(ctx), auth, server)
+}
+
+// Restarts the given servers with the new token.
+func (m *TokenRotation) updateServers(ctx context.Context, hosts []string, auth TTokenSSH, server k3s.ServerTokens) error {
+	for _, host := range hosts {
+		client, err := auth.ForHost(host).SshClient(ctx)
+		if err != nil {
+			return fmt.Errorf("creating ssh config for %s: %s", host, err.Error())
+		}
+		if err := server.UpdateToken(client, m.NewToken.ValueString()); err != nil {
+			return fmt.Errorf("restarting %s with new token: %s", host, err.Error())
+		}
+		tflog.Debug(ctx, fmt.Sprintf("k3s server %s updated with new token", host))
+	}
+	return nil
+}
+
+// Servers present in `inc` that were not part of this rotation.
+func (m TokenRotation) addedServers(ctx context.Context, inc TokenRotation) (added []string) {
+	existing := m.servers(ctx)
+	for _, host := range inc.servers(ctx) {
+		if !slices.Contains(existing, host) {
+			added = append(added, host)
+		}
+	}
+	return
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+type WaitForReady struct {
+	Timeout types.String `tfsdk:"timeout"`
+}
+
+func (m WaitForReady) Schema() schema.Attribute {
+	return m.schema("")
+}
+
+// Schema of a single node, which only waits when it's created.
+func (m WaitForReady) CreateOnlySchema() schema.Attribute {
+	return m.schema(". Only applies when the node is created, setting or changing it afterwards doesn't wait on the existing node")
+}
+
+// Schema of a cluster, whose rolling updates wait on each restarted node.
+func (m WaitForReady) RollingUpdateSchema() schema.Attribute {
+	return m.schema(". Rolling updates also wait for each restarted node within this timeout, which is 5m when this isn't set")
+}
+
+func (m WaitForReady) schema(note string) schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Optional: true,
+		Description: "After install, wait for the node to be Ready in kubernetes instead of only the systemd unit being active. " +
+			"Servers additionally wait for the api server and the packaged addons k3s deploys to kube-system, such as coredns, to be available" + note,
+		Attributes: map[string]schema.Attribute{
+			"timeout": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("5m"),
+				MarkdownDescription: "How long to wait for the node to be ready, as a duration such as `10m`",
+			},
+		},
+	}
+}
+
+func NewWaitForReady(ctx context.Context, t basetypes.ObjectValue) WaitForReady {
+	var na WaitForReady
+	t.As(ctx, &na, basetypes.ObjectAsOptions{})
+	return na
+}
+
+func (m *WaitForReady) ToObject(ctx context.Context) basetypes.ObjectValue {
+	return ToObject(ctx, m)
+}
+
+func (m WaitForReady) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"timeout": types.StringType,
+	}
+}
+
+func (m WaitForReady) Validate() error {
+	if m.Timeout.IsNull() || m.Timeout.IsUnknown() {
+		return nil
+	}
+	if _, err := time.ParseDuration(m.Timeout.ValueString()); err != nil {
+		return fmt.Errorf("timeout %s is not a duration: %s", m.Timeout.ValueString(), err.Error())
+	}
+	return nil
+}
+
+func (m WaitForReady) timeout() time.Duration {
+	timeout, err := time.ParseDuration(m.Timeout.ValueString())
+	if err != nil {
+		return 5 * time.Minute
+	}
+	return timeout
+}
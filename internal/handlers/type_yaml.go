@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+// A yaml document stored as a string. Documents that decode to the same
+// contents are equal, so reformatting or reordering keys isn't a change.
+type YamlType struct {
+	basetypes.StringType
+}
+
+var _ basetypes.StringTypable = YamlType{}
+
+func (t YamlType) Equal(o attr.Type) bool {
+	other, ok := o.(YamlType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t YamlType) String() string {
+	return "YamlType"
+}
+
+func (t YamlType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return YamlValue{StringValue: in}, nil
+}
+
+func (t YamlType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	value, diags := t.ValueFromString(ctx, stringValue)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to YamlValue: %v", diags)
+	}
+	return value, nil
+}
+
+func (t YamlType) ValueType(ctx context.Context) attr.Value {
+	return YamlValue{}
+}
+
+type YamlValue struct {
+	basetypes.StringValue
+}
+
+var _ basetypes.StringValuableWithSemanticEquals = YamlValue{}
+var _ xattr.ValidateableAttribute = YamlValue{}
+
+func NewYamlValue(value string) YamlValue {
+	return YamlValue{StringValue: basetypes.NewStringValue(value)}
+}
+
+func NewYamlNull() YamlValue {
+	return YamlValue{StringValue: basetypes.NewStringNull()}
+}
+
+func (v YamlValue) Equal(o attr.Value) bool {
+	other, ok := o.(YamlValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v YamlValue) Type(ctx context.Context) attr.Type {
+	return YamlType{}
+}
+
+// StringSemanticEquals implements basetypes.StringValuableWithSemanticEquals.
+func (v YamlValue) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(YamlValue)
+	if !ok {
+		diags.AddError("Semantic Equality Check Error", fmt.Sprintf("expected a YamlValue, got %T", newValuable))
+		return false, diags
+	}
+
+	return v.semanticEqual(newValue), diags
+}
+
+// ValidateAttribute implements xattr.ValidateableAttribute.
+func (v YamlValue) ValidateAttribute(ctx context.Context, req xattr.ValidateAttributeRequest, resp *xattr.ValidateAttributeResponse) {
+	if v.IsNull() || v.IsUnknown() {
+		return
+	}
+
+	if _, err := k3s.ParseYamlString(v.StringValue); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid yaml", err.Error())
+	}
+}
+
+// Exactly equal, or both known and decoding to the same contents.
+func (v YamlValue) semanticEqual(o YamlValue) bool {
+	if v.Equal(o) {
+		return true
+	}
+	if v.IsUnknown() || o.IsUnknown() {
+		return false
+	}
+
+	a, err := k3s.ParseYamlString(v.StringValue)
+	if err != nil {
+		return false
+	}
+	b, err := k3s.ParseYamlString(o.StringValue)
+	if err != nil {
+		return false
+	}
+	return yamlEqual(a, b)
+}
+
+// Plans the prior value of a yaml attribute when the config only reformats it,
+// so a semantically equal document doesn't plan an update. The attribute has to
+// be Computed for the plan to differ from the config.
+func YamlUseStateWhenEqual() planmodifier.String {
+	return yamlUseStateWhenEqual{}
+}
+
+type yamlUseStateWhenEqual struct{}
+
+func (m yamlUseStateWhenEqual) Description(ctx context.Context) string {
+	return "Keeps the prior yaml document when the config decodes to the same contents"
+}
+
+func (m yamlUseStateWhenEqual) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m yamlUseStateWhenEqual) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.ConfigValue.IsUnknown() {
+		return
+	}
+	// Computed only to allow keeping the prior value, an unset config stays unset
+	resp.PlanValue = req.ConfigValue
+	if req.StateValue.IsNull() || req.StateValue.IsUnknown() {
+		return
+	}
+	if (YamlValue{StringValue: req.StateValue}).semanticEqual(YamlValue{StringValue: req.ConfigValue}) {
+		resp.PlanValue = req.StateValue
+	}
+}
@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+func TestYamlSemanticEquals(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		a        handlers.YamlValue
+		b        handlers.YamlValue
+		expected bool
+	}{
+		{"Same", handlers.NewYamlValue("a: 1\n"), handlers.NewYamlValue("a: 1\n"), true},
+		{"Reformatted", handlers.NewYamlValue("a:    1\nb:\n  - x\n"), handlers.NewYamlValue("a: 1\nb: [x]"), true},
+		{"Reordered", handlers.NewYamlValue("a: 1\nb: 2\n"), handlers.NewYamlValue("b: 2\na: 1\n"), true},
+		{"Nested", handlers.NewYamlValue("a:\n  b: 1\n  c: 2\n"), handlers.NewYamlValue("a: {c: 2, b: 1}"), true},
+		{"Empty and null", handlers.NewYamlValue(""), handlers.NewYamlNull(), true},
+		{"Changed", handlers.NewYamlValue("a: 1\n"), handlers.NewYamlValue("a: 2\n"), false},
+		{"Reordered list", handlers.NewYamlValue("a: [x, y]"), handlers.NewYamlValue("a: [y, x]"), false},
+		{"Unknown", handlers.NewYamlValue("a: 1\n"), handlers.YamlValue{StringValue: basetypes.NewStringUnknown()}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			equal, diags := tc.a.StringSemanticEquals(t.Context(), tc.b)
+			if diags.HasError() {
+				t.Fatalf("Semantic equals shouldn't raise, got %v", diags)
+			}
+			if equal != tc.expected {
+				t.Errorf("Expected %v comparing %s and %s, got %v", tc.expected, tc.a, tc.b, equal)
+			}
+		})
+	}
+}
+
+func TestYamlValidate(t *testing.T) {
+	t.Parallel()
+
+	var resp xattr.ValidateAttributeResponse
+	handlers.NewYamlValue("a: 1\n").ValidateAttribute(t.Context(), xattr.ValidateAttributeRequest{Path: path.Root("config")}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Errorf("Valid yaml shouldn't raise, got %v", resp.Diagnostics)
+	}
+
+	resp = xattr.ValidateAttributeResponse{}
+	handlers.NewYamlValue("a: [1\n").ValidateAttribute(t.Context(), xattr.ValidateAttributeRequest{Path: path.Root("config")}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Errorf("Invalid yaml should raise, got nil")
+	}
+}
+
+func TestYamlUseStateWhenEqual(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		state    types.String
+		config   types.String
+		expected types.String
+	}{
+		{"Reformatted", types.StringValue("a: 1\nb: [x]\n"), types.StringValue("b:\n  - x\na:    1\n"), types.StringValue("a: 1\nb: [x]\n")},
+		{"Changed", types.StringValue("a: 1\n"), types.StringValue("a: 2\n"), types.StringValue("a: 2\n")},
+		{"Creating", types.StringNull(), types.StringValue("a: 1\n"), types.StringValue("a: 1\n")},
+		{"Unset", types.StringValue("a: 1\n"), types.StringNull(), types.StringNull()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := planmodifier.StringRequest{StateValue: tc.state, ConfigValue: tc.config, PlanValue: types.StringUnknown()}
+			resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+			handlers.YamlUseStateWhenEqual().PlanModifyString(t.Context(), req, resp)
+			if !resp.PlanValue.Equal(tc.expected) {
+				t.Errorf("Expected a plan of %s, got %s", tc.expected, resp.PlanValue)
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	tftypes "github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"gopkg.in/yaml.v2"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
 	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
 )
 
@@ -39,3 +42,93 @@ func ToObject[T K3sTypeAttr](ctx context.Context, o T) basetypes.ObjectValue {
 	obj, _ := tftypes.ObjectValueFrom(ctx, o.AttributeTypes(), o)
 	return obj
 }
+
+// Yaml encodes a remote config, an empty config is a null string.
+func yamlString(contents map[any]any) (YamlValue, error) {
+	if len(contents) == 0 {
+		return NewYamlNull(), nil
+	}
+	out, err := yaml.Marshal(contents)
+	if err != nil {
+		return NewYamlNull(), err
+	}
+	return NewYamlValue(string(out)), nil
+}
+
+// Compares a remote yaml file with the managed yaml it was written from. `expected`
+// is what the provider wrote, including any keys it injected on top of `managed`.
+// Injected keys are left out of the comparison. Returns `managed` untouched when
+// semantically equal, otherwise the remote contents so the drift is planned away.
+func driftedYaml(managed YamlValue, expected map[any]any, remote map[any]any) (YamlValue, error) {
+	if managed.IsUnknown() {
+		return managed, nil
+	}
+
+	user, err := k3s.ParseYamlString(managed.StringValue)
+	if err != nil {
+		return managed, err
+	}
+
+	actual := make(map[any]any, len(remote))
+	for key, value := range remote {
+		if userValue, ok := user[key]; !ok || !reflect.DeepEqual(userValue, expected[key]) {
+			if _, injected := expected[key]; injected {
+				continue
+			}
+		}
+		actual[key] = value
+	}
+
+	if yamlEqual(user, actual) {
+		return managed, nil
+	}
+	return yamlString(actual)
+}
+
+// Semantic equality of two decoded yaml documents, empty and missing are equal.
+func yamlEqual(a map[any]any, b map[any]any) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Converts typed config attributes, keyed by their k3s flag, into config
+// entries. Null and unknown attributes are left out.
+func configEntries(ctx context.Context, values map[string]attr.Value) map[any]any {
+	entries := make(map[any]any)
+	for key, value := range values {
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		switch v := value.(type) {
+		case tftypes.String:
+			entries[key] = v.ValueString()
+		case tftypes.List:
+			var elements []string
+			v.ElementsAs(ctx, &elements, false)
+			entries[key] = elements
+		}
+	}
+	return entries
+}
+
+// Merges typed config entries on top of the raw yaml config.
+func mergeConfig(raw YamlValue, entries map[any]any) (string, error) {
+	if len(entries) == 0 {
+		return raw.ValueString(), nil
+	}
+
+	typed, err := yaml.Marshal(entries)
+	if err != nil {
+		return "", err
+	}
+
+	config, err := k3s.ParseYamlString(raw.StringValue, tftypes.StringValue(string(typed)))
+	if err != nil {
+		return "", err
+	}
+
+	merged, err := yaml.Marshal(config)
+	return string(merged), err
+}
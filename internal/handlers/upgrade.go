@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// k3s_server state as of schema version 0.
+type ServerClientModelV0 struct {
+	Auth        types.Object `tfsdk:"auth"`
+	BinDir      types.String `tfsdk:"bin_dir"`
+	K3sConfig   types.String `tfsdk:"config"`
+	K3sRegistry types.String `tfsdk:"registry"`
+	HaConfig    types.Object `tfsdk:"highly_available"`
+	OidcConfig  types.Object `tfsdk:"oidc"`
+	Id          types.String `tfsdk:"id"`
+	Server      types.String `tfsdk:"server"`
+	KubeConfig  types.String `tfsdk:"kubeconfig"`
+	Token       types.String `tfsdk:"token"`
+	Active      types.Bool   `tfsdk:"active"`
+	ClusterAuth types.Object `tfsdk:"cluster_auth"`
+}
+
+// Upgrades to the current state. Attributes added since are null, computed
+// ones among them are filled in by the next read.
+func (m ServerClientModelV0) Upgrade(ctx context.Context) ServerClientModel {
+	return ServerClientModel{
+		Auth:              m.Auth,
+		AuthWo:            types.ObjectNull(AuthWriteOnly{}.AttributeTypes()),
+		AuthWoVersion:     types.Int64Null(),
+		BinDir:            m.BinDir,
+		K3sConfig:         yamlFromString(m.K3sConfig),
+		K3sRegistry:       yamlFromString(m.K3sRegistry),
+		ServerConfig:      types.ObjectNull(ServerConfig{}.AttributeTypes()),
+		HaConfig:          upgradeObject(ctx, m.HaConfig, HaConfig{}.AttributeTypes()),
+		OidcConfig:        upgradeObject(ctx, m.OidcConfig, OidcConfig{}.AttributeTypes()),
+		DatastoreConfig:   types.ObjectNull(DatastoreConfig{}.AttributeTypes()),
+		Id:                m.Id,
+		Server:            m.Server,
+		KubeConfig:        m.KubeConfig,
+		Token:             m.Token,
+		AgentToken:        types.StringNull(),
+		Active:            m.Active,
+		ClusterAuth:       m.ClusterAuth,
+		CertificateExpiry: types.MapNull(types.StringType),
+		WaitForReady:      types.ObjectNull(WaitForReady{}.AttributeTypes()),
+		Timeouts:          DefaultTimeouts(),
+	}
+}
+
+// k3s_agent state as of schema version 0.
+type AgentClientModelV0 struct {
+	Auth           types.Object `tfsdk:"auth"`
+	Server         types.String `tfsdk:"server"`
+	BinDir         types.String `tfsdk:"bin_dir"`
+	KubeConfig     types.String `tfsdk:"kubeconfig"`
+	K3sRegistry    types.String `tfsdk:"registry"`
+	K3sConfig      types.String `tfsdk:"config"`
+	Token          types.String `tfsdk:"token"`
+	AllowDeleteErr types.Bool   `tfsdk:"allow_delete_err"`
+	Id             types.String `tfsdk:"id"`
+	Active         types.Bool   `tfsdk:"active"`
+}
+
+// Upgrades to the current state. Attributes added since are null.
+func (m AgentClientModelV0) Upgrade(ctx context.Context) AgentClientModel {
+	return AgentClientModel{
+		Auth:           m.Auth,
+		AuthWo:         types.ObjectNull(AuthWriteOnly{}.AttributeTypes()),
+		AuthWoVersion:  types.Int64Null(),
+		Server:         m.Server,
+		BinDir:         m.BinDir,
+		KubeConfig:     m.KubeConfig,
+		K3sRegistry:    yamlFromString(m.K3sRegistry),
+		K3sConfig:      yamlFromString(m.K3sConfig),
+		AgentConfig:    types.ObjectNull(AgentConfig{}.AttributeTypes()),
+		Token:          m.Token,
+		AgentToken:     types.StringNull(),
+		TokenWo:        types.StringNull(),
+		TokenWoVersion: types.Int64Null(),
+		AllowDeleteErr: m.AllowDeleteErr,
+		Id:             m.Id,
+		Active:         m.Active,
+		WaitForReady:   types.ObjectNull(WaitForReady{}.AttributeTypes()),
+		Timeouts:       DefaultTimeouts(),
+	}
+}
+
+func yamlFromString(value types.String) YamlValue {
+	if value.IsNull() {
+		return NewYamlNull()
+	}
+	return NewYamlValue(value.ValueString())
+}
+
+// Converts an object to one with attrTypes, attributes it didn't have are null.
+func upgradeObject(ctx context.Context, obj types.Object, attrTypes map[string]attr.Type) types.Object {
+	if obj.IsNull() {
+		return types.ObjectNull(attrTypes)
+	}
+
+	attrs := make(map[string]attr.Value, len(attrTypes))
+	for name, attrType := range attrTypes {
+		if value, ok := obj.Attributes()[name]; ok {
+			attrs[name] = value
+			continue
+		}
+		null, _ := attrType.ValueFromTerraform(ctx, tftypes.NewValue(attrType.TerraformType(ctx), nil))
+		attrs[name] = null
+	}
+
+	upgraded, _ := types.ObjectValue(attrTypes, attrs)
+	return upgraded
+}
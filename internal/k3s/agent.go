@@ -139,7 +139,7 @@ func (a *agent) Preinstall(client ssh_client.SSHClient) error {
 		return err
 	}
 
-	regCommands, err := registryCommands(a.ctx, a.registry)
+	regCommands, err := registryCommands(a.ctx, a.registry, registryPath(a.config))
 	if err != nil {
 		return err
 	}
@@ -237,10 +237,7 @@ func (a *agent) Update(client ssh_client.SSHClient) error {
 }
 
 func (a *agent) dataDir() string {
-	if dir, ok := a.config["data_dir"].(string); ok && dir != "" {
-		return dir
-	}
-	return DATA_DIR
+	return dataDir(a.config)
 }
 
 // Retrieve server token.
@@ -259,6 +256,11 @@ func (a *agent) Resync(client ssh_client.SSHClient) (err error) {
 		return err
 	}
 
+	a.registry, err = getRegistry(client, registryPath(a.config))
+	if err != nil {
+		return err
+	}
+
 	agentEnv, err := a.getAgentEnv(client)
 	if err != nil {
 		return err
@@ -4,6 +4,7 @@ import (
 	"embed"
 	"encoding/base64"
 	"fmt"
+	"strings"
 )
 
 //go:embed assets/*
@@ -29,3 +30,9 @@ func WriteFileCommands(path string, b64Content string) []string {
 		fmt.Sprintf("sudo rm %s.tmp", path),
 	}
 }
+
+// Quotes a value as a single shell word, so it can't end the quoting and
+// run commands of its own.
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -19,3 +19,17 @@ func TestWriteFileCommands(t *testing.T) {
 		t.Error(commands, "Is not equal to", expected)
 	}
 }
+
+func TestShellQuote(t *testing.T) {
+	for value, expected := range map[string]string{
+		"ci runner":             `'ci runner'`,
+		"it's":                  `'it'\''s'`,
+		"'; rm -rf / #":         `''\''; rm -rf / #'`,
+		"":                      `''`,
+		"K10abc::server:secret": `'K10abc::server:secret'`,
+	} {
+		if got := ShellQuote(value); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	}
+}
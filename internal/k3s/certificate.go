@@ -0,0 +1,107 @@
+package k3s
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+type ServerCertificates interface {
+	// Expiry of each certificate in the server tls directory, keyed
+	// by certificate file name and formatted as RFC3339.
+	CertificateExpiry(client ssh_client.SSHRun) (map[string]string, error)
+	// Rotates the certificates of the given services, or all services when none
+	// are passed, and restarts the node. Refreshes the kubeconfig afterwards.
+	RotateCertificates(client ssh_client.SSHClient, services []string) error
+}
+
+// CertificateExpiry implements ServerCertificates.
+func (s *server) CertificateExpiry(client ssh_client.SSHRun) (map[string]string, error) {
+	tlsDir := fmt.Sprintf("%s/server/tls", s.dataDir())
+	res, err := client.Run(fmt.Sprintf(
+		`sudo sh -c 'for cert in %s/*.crt; do [ -f "$cert" ] || continue; echo "# $cert"; cat "$cert"; done'`,
+		tlsDir,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("reading server certificates: %s", err.Error())
+	}
+
+	if len(res) != 1 {
+		return nil, fmt.Errorf("wrong number of results from reading server certificates")
+	}
+
+	return ParseCertificateExpiry(res[0])
+}
+
+// RotateCertificates implements ServerCertificates.
+func (s *server) RotateCertificates(client ssh_client.SSHClient, services []string) (err error) {
+	if err = s.syncConfig(client); err != nil {
+		return
+	}
+
+	command := "sudo k3s certificate rotate"
+	for _, service := range services {
+		command = fmt.Sprintf("%s --service %s", command, service)
+	}
+
+	tflog.Debug(s.ctx, fmt.Sprintf("Rotating certificates with %s", command))
+	if err = client.RunStream([]string{
+		"sudo systemctl stop k3s",
+		command,
+		"sudo systemctl start k3s",
+	}); err != nil {
+		return
+	}
+
+	// The admin kubeconfig is regenerated on start with a new client certificate
+	s.kubeConfig, err = s.getKubeConfig(client)
+	return
+}
+
+// Parses concatenated PEM certificates, each preceded by a `# <path>` line,
+// into a map of certificate file name : expiry.
+func ParseCertificateExpiry(output string) (map[string]string, error) {
+	expiry := make(map[string]string)
+
+	var name string
+	var contents strings.Builder
+	flush := func() error {
+		defer contents.Reset()
+		if name == "" {
+			return nil
+		}
+
+		block, _ := pem.Decode([]byte(contents.String()))
+		if block == nil {
+			return fmt.Errorf("no pem data found in %s", name)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("parsing certificate %s: %s", name, err.Error())
+		}
+		expiry[name] = cert.NotAfter.UTC().Format(time.RFC3339)
+		return nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if file, ok := strings.CutPrefix(line, "# "); ok {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			name = path.Base(strings.TrimSpace(file))
+			continue
+		}
+		contents.WriteString(line + "\n")
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return expiry, nil
+}
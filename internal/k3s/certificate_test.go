@@ -0,0 +1,68 @@
+package k3s_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+func testCertificate(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "k3s"},
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestParseCertificateExpiry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("certificates", func(t *testing.T) {
+		expiry, err := k3s.ParseCertificateExpiry(
+			"# /var/lib/rancher/k3s/server/tls/client-admin.crt\n" +
+				testCertificate(t, time.Date(2026, 5, 25, 15, 5, 44, 0, time.UTC)) +
+				"# /var/lib/rancher/k3s/server/tls/serving-kube-apiserver.crt\n" +
+				testCertificate(t, time.Date(2027, 1, 2, 3, 4, 5, 0, time.UTC)),
+		)
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		if expiry["client-admin.crt"] != "2026-05-25T15:05:44Z" {
+			t.Errorf("Expected client-admin.crt expiry of 2026-05-25T15:05:44Z, got %s", expiry["client-admin.crt"])
+		}
+		if expiry["serving-kube-apiserver.crt"] != "2027-01-02T03:04:05Z" {
+			t.Errorf("Expected serving-kube-apiserver.crt expiry of 2027-01-02T03:04:05Z, got %s", expiry["serving-kube-apiserver.crt"])
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		expiry, err := k3s.ParseCertificateExpiry("")
+		if err != nil || len(expiry) != 0 {
+			t.Errorf("Expected no certificates, got %v %v", expiry, err)
+		}
+	})
+
+	t.Run("bad pem", func(t *testing.T) {
+		if _, err := k3s.ParseCertificateExpiry("# /tls/bad.crt\nnot a certificate"); err == nil {
+			t.Errorf("Bad pem should raise, got nil")
+		}
+	})
+}
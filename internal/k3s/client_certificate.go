@@ -0,0 +1,171 @@
+package k3s
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Interval between checks for the signed certificate.
+const csrPollInterval = time.Second
+
+// How long to wait for a signed certificate when the context has no deadline.
+const csrTimeout = 2 * time.Minute
+
+type ClientCertificate struct {
+	// Common name of the certificate, which kubernetes takes as the user name
+	User string
+	// Organizations of the certificate, which kubernetes takes as the groups
+	Groups []string
+	// Requested lifetime, the signer may cap it to its own maximum
+	Ttl time.Duration
+}
+
+type ClientCertificates interface {
+	// Generates a key and has it signed by the cluster's client CA through an
+	// approved CertificateSigningRequest. Returns the PEM encoded certificate and key.
+	IssueClientCertificate(cert ClientCertificate) (string, string, error)
+}
+
+var _ ClientCertificates = &clientCertificates{}
+
+type clientCertificates struct {
+	ctx       context.Context
+	clientset kubernetes.Interface
+}
+
+// Issues client certificates through the api server of the kubeconfig,
+// which must be allowed to approve certificate signing requests.
+func NewClientCertificates(ctx context.Context, kubeconfig string) (ClientCertificates, error) {
+	clientset, err := newClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientCertificatesForClientset(ctx, clientset), nil
+}
+
+func NewClientCertificatesForClientset(ctx context.Context, clientset kubernetes.Interface) ClientCertificates {
+	return &clientCertificates{ctx: ctx, clientset: clientset}
+}
+
+// IssueClientCertificate implements ClientCertificates.
+func (c *clientCertificates) IssueClientCertificate(cert ClientCertificate) (string, string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("generating key: %s", err.Error())
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("encoding key: %s", err.Error())
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	tflog.MaskMessageStrings(c.ctx, keyPEM)
+
+	request, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: cert.User, Organization: cert.Groups},
+	}, key)
+	if err != nil {
+		return "", "", fmt.Errorf("creating certificate request: %s", err.Error())
+	}
+
+	name, err := csrName()
+	if err != nil {
+		return "", "", err
+	}
+	seconds := int32(cert.Ttl.Seconds())
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{managedByLabel: managedBy},
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:           pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: request}),
+			SignerName:        certificatesv1.KubeAPIServerClientSignerName,
+			ExpirationSeconds: &seconds,
+			Usages:            []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageClientAuth},
+		},
+	}
+
+	csrs := c.clientset.CertificatesV1().CertificateSigningRequests()
+	if csr, err = csrs.Create(c.ctx, csr, metav1.CreateOptions{}); err != nil {
+		return "", "", fmt.Errorf("creating certificate signing request: %s", err.Error())
+	}
+	tflog.Debug(c.ctx, fmt.Sprintf("Certificate signing request %s created for %s", name, cert.User))
+	// Only needed until the certificate is issued
+	defer func() {
+		if err := csrs.Delete(c.ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			tflog.Warn(c.ctx, fmt.Sprintf("Could not delete certificate signing request %s: %s", name, err.Error()))
+		}
+	}()
+
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:           certificatesv1.CertificateApproved,
+		Status:         corev1.ConditionTrue,
+		Reason:         "TerraformApproved",
+		Message:        "Approved by terraform-provider-k3s",
+		LastUpdateTime: metav1.Now(),
+	})
+	if _, err := csrs.UpdateApproval(c.ctx, name, csr, metav1.UpdateOptions{}); err != nil {
+		return "", "", fmt.Errorf("approving certificate signing request: %s", err.Error())
+	}
+	tflog.Debug(c.ctx, fmt.Sprintf("Certificate signing request %s approved", name))
+
+	certPEM, err := c.waitForCertificate(name)
+	if err != nil {
+		return "", "", err
+	}
+	return certPEM, keyPEM, nil
+}
+
+// Polls until the signer issued the certificate of an approved request.
+func (c *clientCertificates) waitForCertificate(name string) (string, error) {
+	ctx := c.ctx
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, csrTimeout)
+		defer cancel()
+	}
+
+	for {
+		csr, err := c.clientset.CertificatesV1().CertificateSigningRequests().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("getting certificate signing request %s: %s", name, err.Error())
+		}
+		for _, condition := range csr.Status.Conditions {
+			if condition.Type == certificatesv1.CertificateDenied || condition.Type == certificatesv1.CertificateFailed {
+				return "", fmt.Errorf("certificate signing request %s %s: %s", name, condition.Type, condition.Message)
+			}
+		}
+		if len(csr.Status.Certificate) > 0 {
+			return string(csr.Status.Certificate), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("certificate signing request %s was not signed in time", name)
+		case <-time.After(csrPollInterval):
+		}
+	}
+}
+
+func csrName() (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("generating certificate signing request name: %s", err.Error())
+	}
+	return fmt.Sprintf("k3s-client-%s", hex.EncodeToString(suffix)), nil
+}
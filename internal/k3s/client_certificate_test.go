@@ -0,0 +1,108 @@
+package k3s_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+// Signs approved requests like the kube-controller-manager's signer would.
+func signingReactor(t *testing.T, clientset *fake.Clientset) k8stesting.ReactionFunc {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "k3s-client-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, err := clientset.Tracker().Get(action.GetResource(), "", action.(k8stesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		csr := obj.(*certificatesv1.CertificateSigningRequest).DeepCopy()
+		if !slices.ContainsFunc(csr.Status.Conditions, func(c certificatesv1.CertificateSigningRequestCondition) bool {
+			return c.Type == certificatesv1.CertificateApproved
+		}) {
+			return true, csr, nil
+		}
+
+		block, _ := pem.Decode(csr.Spec.Request)
+		request, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			t.Fatalf("Bad certificate request: %v", err.Error())
+		}
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      request.Subject,
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Duration(*csr.Spec.ExpirationSeconds) * time.Second),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, ca, request.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("Signing certificate: %v", err.Error())
+		}
+		csr.Status.Certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		return true, csr, nil
+	}
+}
+
+func TestIssueClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("get", "certificatesigningrequests", signingReactor(t, clientset))
+	certificates := k3s.NewClientCertificatesForClientset(t.Context(), clientset)
+
+	certPEM, keyPEM, err := certificates.IssueClientCertificate(k3s.ClientCertificate{
+		User:   "jane",
+		Groups: []string{"developers", "oncall"},
+		Ttl:    48 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+
+	block, _ := pem.Decode([]byte(certPEM))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Expected a certificate, got %v", err.Error())
+	}
+	// Organizations are a set, their order isn't kept
+	groups := slices.Sorted(slices.Values(cert.Subject.Organization))
+	if cert.Subject.CommonName != "jane" || !slices.Equal(groups, []string{"developers", "oncall"}) {
+		t.Errorf("Unexpected subject %v", cert.Subject)
+	}
+
+	block, _ = pem.Decode([]byte(keyPEM))
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("Expected an EC key, got %v", err.Error())
+	}
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		t.Errorf("Key does not match the certificate")
+	}
+
+	csrs, _ := clientset.CertificatesV1().CertificateSigningRequests().List(t.Context(), metav1.ListOptions{})
+	if len(csrs.Items) != 0 {
+		t.Errorf("Expected the certificate signing request to be cleaned up, got %d", len(csrs.Items))
+	}
+}
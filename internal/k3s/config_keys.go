@@ -0,0 +1,156 @@
+package k3s
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agext/levenshtein"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v2"
+)
+
+type ConfigKey struct {
+	Type  string `yaml:"type"`
+	Since string `yaml:"since"`
+}
+
+type configCatalog struct {
+	Agent  map[string]ConfigKey `yaml:"agent"`
+	Server map[string]ConfigKey `yaml:"server"`
+}
+
+// Keys accepted by the config file of an agent, or of a server when `server` is true.
+func ConfigKeys(server bool) (map[string]ConfigKey, error) {
+	contents, err := assets.ReadFile("assets/config-keys.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog configCatalog
+	if err := yaml.Unmarshal(contents, &catalog); err != nil {
+		return nil, fmt.Errorf("parsing config key catalog: %s", err.Error())
+	}
+
+	keys := make(map[string]ConfigKey, len(catalog.Agent)+len(catalog.Server))
+	for key, value := range catalog.Agent {
+		keys[key] = value
+	}
+	if server {
+		for key, value := range catalog.Server {
+			keys[key] = value
+		}
+	}
+	return keys, nil
+}
+
+// A key missing from the catalog. The catalog can lag behind k3s releases, so
+// this is only a warning that the key may be a typo.
+type UnknownConfigKeyError struct {
+	Node       string
+	Key        string
+	Suggestion string
+}
+
+func (e *UnknownConfigKeyError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown k3s %s config key %q, did you mean %q?", e.Node, e.Key, e.Suggestion)
+	}
+	return fmt.Sprintf("unknown k3s %s config key %q", e.Node, e.Key)
+}
+
+// Validates the keys of a k3s config, and the type of their values, against the known
+// keys. Keys introduced after `version` are rejected, unless no version is pinned.
+// Keys missing from the catalog are returned as an *UnknownConfigKeyError.
+func ValidateConfigKeys(config map[any]any, server bool, version string) ([]error, error) {
+	known, err := ConfigKeys(server)
+	if err != nil {
+		return nil, err
+	}
+
+	node := "agent"
+	if server {
+		node = "server"
+	}
+
+	// Sorted for stable diagnostics
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, fmt.Sprint(key))
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		// `key+` appends to the value of an earlier config file
+		name := strings.TrimSuffix(key, "+")
+
+		spec, ok := known[name]
+		if !ok {
+			errs = append(errs, &UnknownConfigKeyError{Node: node, Key: key, Suggestion: suggestKey(name, known)})
+			continue
+		}
+
+		if spec.Since != "" && version != "" && semver.IsValid(version) && semver.Compare(version, spec.Since) < 0 {
+			errs = append(errs, fmt.Errorf("k3s config key %q requires k3s %s or later, got %s", key, spec.Since, version))
+		}
+
+		if !validConfigValue(spec.Type, config[key]) {
+			errs = append(errs, fmt.Errorf("k3s config key %q expects a %s, got %v", key, spec.Type, config[key]))
+		}
+	}
+	return errs, nil
+}
+
+func validConfigValue(kind string, value any) bool {
+	switch v := value.(type) {
+	case map[any]any:
+		return false
+	case []any:
+		if kind != "list" {
+			return false
+		}
+		for _, element := range v {
+			if !validConfigValue("string", element) {
+				return false
+			}
+		}
+		return true
+	case string:
+		switch kind {
+		case "bool":
+			_, err := strconv.ParseBool(v)
+			return err == nil
+		case "int":
+			_, err := strconv.Atoi(v)
+			return err == nil
+		}
+		return true
+	case bool:
+		return kind != "int"
+	case int:
+		return kind != "bool"
+	}
+	return true
+}
+
+// The closest known key to a mistyped key, if any is close enough.
+func suggestKey(key string, known map[string]ConfigKey) string {
+	// The most common typo is yaml style underscores
+	if _, ok := known[strings.ReplaceAll(key, "_", "-")]; ok {
+		return strings.ReplaceAll(key, "_", "-")
+	}
+
+	best, bestDistance := "", 4
+	for candidate := range known {
+		distance := levenshtein.Distance(key, candidate, nil)
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if bestDistance > len(key)/2 {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,115 @@
+package k3s_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+func TestValidateConfigKeys(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		config   map[any]any
+		server   bool
+		version  string
+		expected string
+	}{
+		{
+			name: "Valid server",
+			config: map[any]any{
+				"tls-san":               []any{"k3s.example.com"},
+				"write-kubeconfig-mode": "0644",
+				"cluster-init":          true,
+				"node-label+":           []any{"role=server"},
+				"https-listen-port":     6443,
+			},
+			server:   true,
+			expected: "[]",
+		},
+		{
+			name:     "Underscores",
+			config:   map[any]any{"tls_san": []any{"k3s.example.com"}},
+			server:   true,
+			expected: `[unknown k3s server config key "tls_san", did you mean "tls-san"?]`,
+		},
+		{
+			name:     "Typo",
+			config:   map[any]any{"node-lable": "a=b"},
+			expected: `[unknown k3s agent config key "node-lable", did you mean "node-label"?]`,
+		},
+		{
+			name:     "Server key on agent",
+			config:   map[any]any{"cluster-init": true},
+			expected: `[unknown k3s agent config key "cluster-init"]`,
+		},
+		{
+			name:     "Wrong type",
+			config:   map[any]any{"secrets-encryption": "yes please"},
+			server:   true,
+			expected: `[k3s config key "secrets-encryption" expects a bool, got yes please]`,
+		},
+		{
+			name:     "Too old",
+			config:   map[any]any{"embedded-registry": true},
+			server:   true,
+			version:  "v1.25.4+k3s1",
+			expected: `[k3s config key "embedded-registry" requires k3s v1.26.13 or later, got v1.25.4+k3s1]`,
+		},
+		{
+			name: "Recent keys",
+			config: map[any]any{
+				"secrets-encryption-provider":      "secretbox",
+				"etcd-s3-retention":                10,
+				"etcd-s3-proxy":                    "http://proxy:3128",
+				"etcd-snapshot-reconcile-interval": "10m",
+				"supervisor-metrics":               true,
+			},
+			server:   true,
+			version:  "v1.32.2+k3s1",
+			expected: "[]",
+		},
+		{
+			name:     "Recent key too old",
+			config:   map[any]any{"etcd-s3-retention": 10},
+			server:   true,
+			version:  "v1.29.4+k3s1",
+			expected: `[k3s config key "etcd-s3-retention" requires k3s v1.30.10 or later, got v1.29.4+k3s1]`,
+		},
+		{
+			name:     "Unpinned version",
+			config:   map[any]any{"embedded-registry": true},
+			server:   true,
+			expected: "[]",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs, err := k3s.ValidateConfigKeys(tc.config, tc.server, tc.version)
+			if err != nil {
+				t.Fatalf("Expected nil err but found: %v", err.Error())
+			}
+			if got := fmt.Sprint(errs); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateConfigKeysUnknown(t *testing.T) {
+	t.Parallel()
+
+	errs, err := k3s.ValidateConfigKeys(map[any]any{"node-lable": "a=b"}, false, "")
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	var unknown *k3s.UnknownConfigKeyError
+	if len(errs) != 1 || !errors.As(errs[0], &unknown) {
+		t.Fatalf("Expected an unknown key error, got %v", errs)
+	}
+	if unknown.Suggestion != "node-label" {
+		t.Errorf("Expected a suggestion of node-label, got %q", unknown.Suggestion)
+	}
+}
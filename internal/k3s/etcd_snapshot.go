@@ -0,0 +1,132 @@
+package k3s
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+type ServerEtcdSnapshots interface {
+	// Lists the etcd snapshots known to the server
+	EtcdSnapshots(ssh_client.SSHRun) ([]EtcdSnapshot, error)
+}
+
+// A single etcd snapshot as reported by `k3s etcd-snapshot ls`.
+type EtcdSnapshot struct {
+	Name      string
+	NodeName  string
+	Location  string
+	Size      int64
+	CreatedAt string
+}
+
+// Subset of the ETCDSnapshotFile list returned by `k3s etcd-snapshot ls -o json`.
+type etcdSnapshotFileList struct {
+	Items []struct {
+		Spec struct {
+			SnapshotName string `json:"snapshotName"`
+			NodeName     string `json:"nodeName"`
+			Location     string `json:"location"`
+		} `json:"spec"`
+		Status struct {
+			Size         string `json:"size"`
+			CreationTime string `json:"creationTime"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// EtcdSnapshots implements ServerEtcdSnapshots.
+func (s *server) EtcdSnapshots(client ssh_client.SSHRun) ([]EtcdSnapshot, error) {
+	res, err := client.Run("sudo k3s etcd-snapshot ls -o json")
+	if err != nil {
+		// Older k3s releases do not support the output flag, fall back to the table
+		res, err = client.Run("sudo k3s etcd-snapshot ls")
+		if err != nil {
+			return nil, fmt.Errorf("listing etcd snapshots: %s", err.Error())
+		}
+	}
+
+	if len(res) != 1 {
+		return nil, fmt.Errorf("wrong number of results from etcd snapshot list")
+	}
+
+	return ParseEtcdSnapshots(res[0])
+}
+
+// Parses the output of `k3s etcd-snapshot ls`, either as json or as the
+// default table output.
+func ParseEtcdSnapshots(output string) ([]EtcdSnapshot, error) {
+	trimmed := strings.TrimSpace(output)
+	if strings.HasPrefix(trimmed, "{") {
+		return parseEtcdSnapshotsJson(trimmed)
+	}
+	return parseEtcdSnapshotsTable(trimmed)
+}
+
+func parseEtcdSnapshotsJson(output string) ([]EtcdSnapshot, error) {
+	var list etcdSnapshotFileList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("parsing etcd snapshot list: %s", err.Error())
+	}
+
+	snapshots := make([]EtcdSnapshot, 0, len(list.Items))
+	for _, item := range list.Items {
+		var size int64
+		if item.Status.Size != "" {
+			quantity, err := resource.ParseQuantity(item.Status.Size)
+			if err != nil {
+				return nil, fmt.Errorf("parsing size of snapshot %s: %s", item.Spec.SnapshotName, err.Error())
+			}
+			size = quantity.Value()
+		}
+
+		snapshots = append(snapshots, EtcdSnapshot{
+			Name:      item.Spec.SnapshotName,
+			NodeName:  item.Spec.NodeName,
+			Location:  item.Spec.Location,
+			Size:      size,
+			CreatedAt: item.Status.CreationTime,
+		})
+	}
+
+	return snapshots, nil
+}
+
+// Table output is `Name Location Size Created`, where older releases omit the location.
+func parseEtcdSnapshotsTable(output string) ([]EtcdSnapshot, error) {
+	snapshots := []EtcdSnapshot{}
+	whitespace := regexp.MustCompile(`\s+`)
+
+	for i, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || (i == 0 && strings.HasPrefix(line, "Name")) {
+			continue
+		}
+
+		fields := whitespace.Split(line, -1)
+		var snapshot EtcdSnapshot
+		var size string
+		switch len(fields) {
+		case 4:
+			snapshot.Name, snapshot.Location, size, snapshot.CreatedAt = fields[0], fields[1], fields[2], fields[3]
+		case 3:
+			snapshot.Name, size, snapshot.CreatedAt = fields[0], fields[1], fields[2]
+		default:
+			return nil, fmt.Errorf("unexpected etcd snapshot line: %s", line)
+		}
+
+		parsed, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing size of snapshot %s: %s", snapshot.Name, err.Error())
+		}
+		snapshot.Size = parsed
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,73 @@
+package k3s_test
+
+import (
+	"testing"
+
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+func TestParseEtcdSnapshots(t *testing.T) {
+	t.Parallel()
+
+	t.Run("json", func(t *testing.T) {
+		snapshots, err := k3s.ParseEtcdSnapshots(`{
+  "apiVersion": "v1",
+  "items": [{
+    "metadata": {"name": "local-on-demand-node-1-1748185544-c1f2a3"},
+    "spec": {
+      "snapshotName": "on-demand-node-1-1748185544",
+      "nodeName": "node-1",
+      "location": "file:///var/lib/rancher/k3s/server/db/snapshots/on-demand-node-1-1748185544"
+    },
+    "status": {"size": "2654240", "creationTime": "2025-05-25T15:05:44Z", "readyToUse": true}
+  }]
+}`)
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		if len(snapshots) != 1 {
+			t.Fatalf("Expected 1 snapshot, got %d", len(snapshots))
+		}
+		expected := k3s.EtcdSnapshot{
+			Name:      "on-demand-node-1-1748185544",
+			NodeName:  "node-1",
+			Location:  "file:///var/lib/rancher/k3s/server/db/snapshots/on-demand-node-1-1748185544",
+			Size:      2654240,
+			CreatedAt: "2025-05-25T15:05:44Z",
+		}
+		if snapshots[0] != expected {
+			t.Errorf("Expected %v, got %v", expected, snapshots[0])
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		snapshots, err := k3s.ParseEtcdSnapshots(`Name                        Location                                                                    Size    Created
+on-demand-node-1-1748185544 file:///var/lib/rancher/k3s/server/db/snapshots/on-demand-node-1-1748185544 2654240 2025-05-25T15:05:44Z
+`)
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		if len(snapshots) != 1 {
+			t.Fatalf("Expected 1 snapshot, got %d", len(snapshots))
+		}
+		if snapshots[0].Size != 2654240 || snapshots[0].CreatedAt != "2025-05-25T15:05:44Z" {
+			t.Errorf("Unexpected snapshot %v", snapshots[0])
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		snapshots, err := k3s.ParseEtcdSnapshots(`{"apiVersion": "v1", "items": []}`)
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		if len(snapshots) != 0 {
+			t.Errorf("Expected no snapshots, got %d", len(snapshots))
+		}
+	})
+
+	t.Run("bad table", func(t *testing.T) {
+		if _, err := k3s.ParseEtcdSnapshots("Name Size Created\nfoo notanumber now"); err == nil {
+			t.Errorf("Bad size should raise, got nil")
+		}
+	})
+}
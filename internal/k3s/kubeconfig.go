@@ -0,0 +1,146 @@
+package k3s
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+
+	"k8s.io/client-go/tools/clientcmd"
+	api "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Port k3s serves the api on, unless https-listen-port is set.
+const defaultApiPort = "6443"
+
+// Context the kubeconfig points to. Falls back to its only context when
+// current-context is unset, as kubectl would need one passed otherwise.
+func CurrentKubeConfigContext(config *api.Config) (string, *api.Context, error) {
+	if context, ok := config.Contexts[config.CurrentContext]; ok && context != nil {
+		return config.CurrentContext, context, nil
+	}
+	if config.CurrentContext == "" && len(config.Contexts) == 1 {
+		for name, context := range config.Contexts {
+			if context != nil {
+				return name, context, nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("context %q not found in kubeconfig", config.CurrentContext)
+}
+
+// Cluster and user of the kubeconfig's current context.
+func CurrentKubeConfigEntries(config *api.Config) (*api.Cluster, *api.AuthInfo, error) {
+	_, context, err := CurrentKubeConfigContext(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	cluster, ok := config.Clusters[context.Cluster]
+	if !ok || cluster == nil {
+		return nil, nil, fmt.Errorf("cluster %q not found in kubeconfig", context.Cluster)
+	}
+	user, ok := config.AuthInfos[context.AuthInfo]
+	if !ok || user == nil {
+		return nil, nil, fmt.Errorf("user %q not found in kubeconfig", context.AuthInfo)
+	}
+	return cluster, user, nil
+}
+
+// Points an api server url at another host. The port of the url is kept
+// unless the host comes with its own.
+func ReplaceServerHost(server string, host string) (string, error) {
+	parsed, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("parsing server %s: %s", server, err.Error())
+	}
+
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		parsed.Host = host
+	} else {
+		port := parsed.Port()
+		if port == "" {
+			port = defaultApiPort
+		}
+		parsed.Host = net.JoinHostPort(host, port)
+	}
+	if parsed.Scheme == "" {
+		parsed.Scheme = "https"
+	}
+	return parsed.String(), nil
+}
+
+// Changes the port of an api server url.
+func ReplaceServerPort(server string, port int64) (string, error) {
+	parsed, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("parsing server %s: %s", server, err.Error())
+	}
+	parsed.Host = net.JoinHostPort(parsed.Hostname(), fmt.Sprint(port))
+	return parsed.String(), nil
+}
+
+// Kubeconfig of one cluster, with the name it gets once merged with others.
+type NamedKubeConfig struct {
+	Name       string
+	KubeConfig string
+}
+
+// Merges the current context of each kubeconfig into one document, with the
+// context, cluster and user all named after the entry. Entries repeating a
+// name are dropped when identical, conflicting ones are an error. The
+// current context defaults to the first entry.
+func MergeKubeConfigs(entries []NamedKubeConfig, currentContext string) (string, error) {
+	if len(entries) == 0 {
+		return "", fmt.Errorf("at least one kubeconfig must be passed")
+	}
+
+	merged := api.NewConfig()
+	for i, entry := range entries {
+		if entry.Name == "" {
+			return "", fmt.Errorf("kubeconfig %d has no name", i)
+		}
+
+		config, err := clientcmd.Load([]byte(entry.KubeConfig))
+		if err != nil {
+			return "", fmt.Errorf("parsing kubeconfig %s: %s", entry.Name, err.Error())
+		}
+		_, context, err := CurrentKubeConfigContext(config)
+		if err != nil {
+			return "", fmt.Errorf("kubeconfig %s: %s", entry.Name, err.Error())
+		}
+		cluster, user, err := CurrentKubeConfigEntries(config)
+		if err != nil {
+			return "", fmt.Errorf("kubeconfig %s: %s", entry.Name, err.Error())
+		}
+
+		if existing, ok := merged.Clusters[entry.Name]; ok {
+			if !reflect.DeepEqual(existing, cluster) || !reflect.DeepEqual(merged.AuthInfos[entry.Name], user) ||
+				merged.Contexts[entry.Name].Namespace != context.Namespace {
+				return "", fmt.Errorf("kubeconfig %s is passed twice with different contents", entry.Name)
+			}
+			continue
+		}
+
+		merged.Clusters[entry.Name] = cluster
+		merged.AuthInfos[entry.Name] = user
+		merged.Contexts[entry.Name] = &api.Context{
+			Cluster:   entry.Name,
+			AuthInfo:  entry.Name,
+			Namespace: context.Namespace,
+		}
+	}
+
+	merged.CurrentContext = entries[0].Name
+	if currentContext != "" {
+		if _, ok := merged.Contexts[currentContext]; !ok {
+			return "", fmt.Errorf("current context %s is not one of the kubeconfigs", currentContext)
+		}
+		merged.CurrentContext = currentContext
+	}
+
+	rendered, err := clientcmd.Write(*merged)
+	if err != nil {
+		return "", err
+	}
+	return string(rendered), nil
+}
@@ -0,0 +1,153 @@
+package k3s_test
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	api "k8s.io/client-go/tools/clientcmd/api"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+func TestCurrentKubeConfigEntries(t *testing.T) {
+	t.Parallel()
+
+	named := api.NewConfig()
+	named.Clusters["prod"] = &api.Cluster{Server: "https://10.0.0.1:6443"}
+	named.AuthInfos["admin"] = &api.AuthInfo{Token: "abc"}
+	named.Contexts["prod-admin"] = &api.Context{Cluster: "prod", AuthInfo: "admin"}
+
+	t.Run("Sole context", func(t *testing.T) {
+		cluster, user, err := k3s.CurrentKubeConfigEntries(named)
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		if cluster.Server != "https://10.0.0.1:6443" || user.Token != "abc" {
+			t.Errorf("Unexpected entries %v %v", cluster, user)
+		}
+	})
+
+	t.Run("Missing context", func(t *testing.T) {
+		config := named.DeepCopy()
+		config.CurrentContext = "default"
+		if _, _, err := k3s.CurrentKubeConfigEntries(config); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Missing user", func(t *testing.T) {
+		config := named.DeepCopy()
+		config.CurrentContext = "prod-admin"
+		delete(config.AuthInfos, "admin")
+		if _, _, err := k3s.CurrentKubeConfigEntries(config); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestReplaceServerHost(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		server   string
+		host     string
+		expected string
+	}{
+		{"https://127.0.0.1:6443", "10.0.0.1", "https://10.0.0.1:6443"},
+		{"https://127.0.0.1:7443", "lb.example.com", "https://lb.example.com:7443"},
+		{"https://127.0.0.1:6443", "lb.example.com:443", "https://lb.example.com:443"},
+		{"https://127.0.0.1", "10.0.0.1", "https://10.0.0.1:6443"},
+		{"https://127.0.0.1:6443", "fd00::1", "https://[fd00::1]:6443"},
+	} {
+		got, err := k3s.ReplaceServerHost(tc.server, tc.host)
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		if got != tc.expected {
+			t.Errorf("%s with %s: expected %s, got %s", tc.server, tc.host, tc.expected, got)
+		}
+	}
+
+	got, err := k3s.ReplaceServerPort("https://lb.example.com:6443", 443)
+	if err != nil || got != "https://lb.example.com:443" {
+		t.Errorf("Expected https://lb.example.com:443, got %s %v", got, err)
+	}
+}
+
+// Kubeconfig as k3s writes it, everything named default.
+func clusterKubeConfig(t *testing.T, server string) string {
+	config := api.NewConfig()
+	config.Clusters["default"] = &api.Cluster{Server: server, CertificateAuthorityData: []byte("ca")}
+	config.AuthInfos["default"] = &api.AuthInfo{ClientCertificateData: []byte(server), ClientKeyData: []byte("key")}
+	config.Contexts["default"] = &api.Context{Cluster: "default", AuthInfo: "default"}
+	config.CurrentContext = "default"
+	rendered, err := clientcmd.Write(*config)
+	if err != nil {
+		t.Fatalf("Writing kubeconfig: %v", err.Error())
+	}
+	return string(rendered)
+}
+
+func TestMergeKubeConfigs(t *testing.T) {
+	t.Parallel()
+
+	prod := clusterKubeConfig(t, "https://10.0.0.1:6443")
+	staging := clusterKubeConfig(t, "https://10.0.1.1:6443")
+
+	t.Run("Merged", func(t *testing.T) {
+		merged, err := k3s.MergeKubeConfigs([]k3s.NamedKubeConfig{
+			{Name: "prod", KubeConfig: prod},
+			{Name: "staging", KubeConfig: staging},
+			{Name: "prod", KubeConfig: prod},
+		}, "staging")
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+
+		config, err := clientcmd.Load([]byte(merged))
+		if err != nil {
+			t.Fatalf("Expected a valid kubeconfig, got %v", err.Error())
+		}
+		if len(config.Clusters) != 2 || len(config.AuthInfos) != 2 || len(config.Contexts) != 2 {
+			t.Errorf("Expected two distinct clusters, users and contexts, got %v", config.Contexts)
+		}
+		if config.CurrentContext != "staging" {
+			t.Errorf("Expected staging as the current context, got %s", config.CurrentContext)
+		}
+		context := config.Contexts["prod"]
+		if context.Cluster != "prod" || context.AuthInfo != "prod" || config.Clusters["prod"].Server != "https://10.0.0.1:6443" {
+			t.Errorf("Unexpected prod context %v", context)
+		}
+		if string(config.AuthInfos["staging"].ClientCertificateData) != "https://10.0.1.1:6443" {
+			t.Errorf("Expected the staging user to keep its credentials")
+		}
+	})
+
+	t.Run("Default current context", func(t *testing.T) {
+		merged, err := k3s.MergeKubeConfigs([]k3s.NamedKubeConfig{{Name: "prod", KubeConfig: prod}}, "")
+		if err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		config, _ := clientcmd.Load([]byte(merged))
+		if config.CurrentContext != "prod" {
+			t.Errorf("Expected the first entry as the current context, got %s", config.CurrentContext)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		entries []k3s.NamedKubeConfig
+		current string
+	}{
+		{"no kubeconfigs", nil, ""},
+		{"no name", []k3s.NamedKubeConfig{{KubeConfig: prod}}, ""},
+		{"bad kubeconfig", []k3s.NamedKubeConfig{{Name: "prod", KubeConfig: "{"}}, ""},
+		{"conflicting names", []k3s.NamedKubeConfig{{Name: "prod", KubeConfig: prod}, {Name: "prod", KubeConfig: staging}}, ""},
+		{"unknown current context", []k3s.NamedKubeConfig{{Name: "prod", KubeConfig: prod}}, "staging"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := k3s.MergeKubeConfigs(tc.entries, tc.current); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Longest a drain waits on disruption budgets and evicted pods terminating.
+const drainTimeout = 5 * time.Minute
+
+// Interval between retried evictions and checks on evicted pods.
+const drainPollInterval = 5 * time.Second
+
+type NodeRemoval interface {
+	// Cordons the node, evicts its pods and waits for them to terminate,
+	// then deletes the node object, so workloads move before k3s is
+	// uninstalled. A drain that can't finish errors with the node kept.
+	// For servers on embedded etcd, k3s removes the etcd member of a
+	// deleted node.
+	RemoveNode(hostname string) error
+}
+
+var _ NodeRemoval = &nodeRemoval{}
+
+type nodeRemoval struct {
+	ctx       context.Context
+	clientset kubernetes.Interface
+}
+
+// Removes nodes through the api server of the kubeconfig.
+func NewNodeRemoval(ctx context.Context, kubeconfig string) (NodeRemoval, error) {
+	clientset, err := newClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewNodeRemovalForClientset(ctx, clientset), nil
+}
+
+func NewNodeRemovalForClientset(ctx context.Context, clientset kubernetes.Interface) NodeRemoval {
+	return &nodeRemoval{ctx: ctx, clientset: clientset}
+}
+
+// RemoveNode implements NodeRemoval.
+func (n *nodeRemoval) RemoveNode(hostname string) error {
+	nodes := n.clientset.CoreV1().Nodes()
+	cordon := []byte(`{"spec":{"unschedulable":true}}`)
+	if _, err := nodes.Patch(n.ctx, hostname, types.StrategicMergePatchType, cordon, metav1.PatchOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			tflog.Warn(n.ctx, fmt.Sprintf("Node %s is already gone", hostname))
+			return nil
+		}
+		return fmt.Errorf("cordoning node %s: %s", hostname, err.Error())
+	}
+	tflog.Debug(n.ctx, fmt.Sprintf("Node %s cordoned", hostname))
+
+	if err := n.drain(hostname); err != nil {
+		return fmt.Errorf("draining node %s: %s", hostname, err.Error())
+	}
+	tflog.Debug(n.ctx, fmt.Sprintf("Node %s drained", hostname))
+
+	if err := nodes.Delete(n.ctx, hostname, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting node %s: %s", hostname, err.Error())
+	}
+	tflog.Debug(n.ctx, fmt.Sprintf("Node %s deleted", hostname))
+	return nil
+}
+
+// Evicts the pods of the node and waits for them to be gone, within the
+// drain timeout.
+func (n *nodeRemoval) drain(hostname string) error {
+	ctx, cancel := context.WithTimeout(n.ctx, drainTimeout)
+	defer cancel()
+
+	pods, err := n.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", hostname).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("listing pods: %s", err.Error())
+	}
+
+	var evicted []corev1.Pod
+	for _, pod := range pods.Items {
+		if !evictable(pod) {
+			continue
+		}
+		if err := n.evict(ctx, pod); err != nil {
+			return err
+		}
+		evicted = append(evicted, pod)
+	}
+	return n.waitForDeletion(ctx, evicted)
+}
+
+// Evicts a pod, retrying while a disruption budget refuses it.
+func (n *nodeRemoval) evict(ctx context.Context, pod corev1.Pod) error {
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+	for {
+		err := n.clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		if err == nil || apierrors.IsNotFound(err) {
+			return nil
+		}
+		if !apierrors.IsTooManyRequests(err) {
+			return fmt.Errorf("evicting pod %s/%s: %s", pod.Namespace, pod.Name, err.Error())
+		}
+		tflog.Info(ctx, fmt.Sprintf("Eviction of pod %s/%s blocked, retrying: %s", pod.Namespace, pod.Name, err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("evicting pod %s/%s: %s", pod.Namespace, pod.Name, err.Error())
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// Waits until none of the pods exist anymore. A pod of the same name with
+// another uid, such as one recreated by a statefulset, counts as gone.
+func (n *nodeRemoval) waitForDeletion(ctx context.Context, pods []corev1.Pod) error {
+	for {
+		var remaining []string
+		for _, pod := range pods {
+			current, err := n.clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("getting pod %s/%s: %s", pod.Namespace, pod.Name, err.Error())
+			}
+			remaining = append(remaining, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		tflog.Info(ctx, fmt.Sprintf("Waiting for evicted pods to terminate: %s", strings.Join(remaining, ", ")))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("evicted pods %s did not terminate", strings.Join(remaining, ", "))
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// Daemonset and static pods are tied to the node, evicting them is pointless.
+func evictable(pod corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if _, mirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; mirror {
+		return false
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package k3s_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+func TestRemoveNode(t *testing.T) {
+	t.Parallel()
+
+	pod := func(name string, owner string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec:       corev1.PodSpec{NodeName: "agent-1"},
+		}
+		if owner != "" {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: owner, Name: "owner"}}
+		}
+		return p
+	}
+	clientset := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "agent-1"}},
+		pod("web", "ReplicaSet"),
+		pod("logs", "DaemonSet"),
+	)
+	// The api server deletes the pod once the eviction is allowed
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		name := action.(k8stesting.CreateAction).GetObject().(metav1.Object).GetName()
+		return true, nil, clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), action.GetNamespace(), name)
+	})
+	removal := k3s.NewNodeRemovalForClientset(t.Context(), clientset)
+
+	if err := removal.RemoveNode("agent-1"); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+
+	var evicted []string
+	for _, action := range clientset.Actions() {
+		if action.GetSubresource() == "eviction" {
+			evicted = append(evicted, action.(k8stesting.CreateAction).GetObject().(metav1.Object).GetName())
+		}
+	}
+	if !slices.Equal(evicted, []string{"web"}) {
+		t.Errorf("Expected only the web pod to be evicted, got %v", evicted)
+	}
+	if _, err := clientset.CoreV1().Nodes().Get(t.Context(), "agent-1", metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected the node to be deleted")
+	}
+
+	t.Run("Already gone", func(t *testing.T) {
+		if err := removal.RemoveNode("agent-2"); err != nil {
+			t.Errorf("Expected a missing node to be skipped, got %v", err.Error())
+		}
+	})
+}
+
+func TestRemoveNodeUnfinishedDrain(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*fake.Clientset, k3s.NodeRemoval) {
+		clientset := fake.NewClientset(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "agent-1"}},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps", UID: "1"},
+				Spec:       corev1.PodSpec{NodeName: "agent-1"},
+			},
+		)
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		t.Cleanup(cancel)
+		return clientset, k3s.NewNodeRemovalForClientset(ctx, clientset)
+	}
+	nodeKept := func(t *testing.T, clientset *fake.Clientset) {
+		if _, err := clientset.CoreV1().Nodes().Get(t.Context(), "agent-1", metav1.GetOptions{}); err != nil {
+			t.Errorf("Expected the node to be kept, got %v", err.Error())
+		}
+	}
+
+	t.Run("Blocked eviction", func(t *testing.T) {
+		clientset, removal := setup(t)
+		clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		})
+
+		err := removal.RemoveNode("agent-1")
+		if err == nil || !strings.Contains(err.Error(), "evicting pod apps/db") {
+			t.Errorf("Expected a blocked eviction to raise, got %v", err)
+		}
+		nodeKept(t, clientset)
+	})
+
+	t.Run("Pod not terminating", func(t *testing.T) {
+		clientset, removal := setup(t)
+		clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return action.GetSubresource() == "eviction", nil, nil
+		})
+
+		err := removal.RemoveNode("agent-1")
+		if err == nil || !strings.Contains(err.Error(), "evicted pods apps/db did not terminate") {
+			t.Errorf("Expected a pod that's still running to raise, got %v", err)
+		}
+		nodeKept(t, clientset)
+	})
+}
@@ -0,0 +1,99 @@
+package k3s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Nodes operated on at once when no parallelism is given.
+const DefaultParallelism = 5
+
+// The error of a single node of a NodePool run.
+type NodeError struct {
+	Node string
+	Err  error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Node, e.Err.Error())
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// Runs an operation across many nodes, a bounded number at a time.
+type NodePool struct {
+	ctx         context.Context
+	parallelism int
+}
+
+// Pool running at most `parallelism` nodes at once, DefaultParallelism
+// if it isn't positive.
+func NewNodePool(ctx context.Context, parallelism int) *NodePool {
+	if parallelism < 1 {
+		parallelism = DefaultParallelism
+	}
+	return &NodePool{ctx: ctx, parallelism: parallelism}
+}
+
+// Runs fn for every node, by index into `nodes`. Every node is run even when
+// others fail, each failure is returned as a *NodeError joined with the rest.
+// Nodes still waiting for a slot when the context is done aren't started.
+func (p *NodePool) Run(nodes []string, fn func(i int) error) error {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, p.parallelism)
+	errs := make([]error, len(nodes))
+
+	for i, node := range nodes {
+		if err := p.ctx.Err(); err != nil {
+			errs[i] = &NodeError{Node: node, Err: err}
+			continue
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-p.ctx.Done():
+			errs[i] = &NodeError{Node: node, Err: p.ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := fn(i); err != nil {
+				errs[i] = &NodeError{Node: node, Err: err}
+				return
+			}
+			tflog.Debug(p.ctx, fmt.Sprintf("Node %s done", node))
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Runs fn over the nodes in batches of `batch`, each batch in parallel. The
+// first batch with a failure halts the run with the nodes after it untouched,
+// so a bad change can't take every node down at once.
+func (p *NodePool) Rolling(nodes []string, batch int, fn func(i int) error) error {
+	if batch < 1 {
+		batch = 1
+	}
+	for start := 0; start < len(nodes); start += batch {
+		end := min(start+batch, len(nodes))
+		if err := p.Run(nodes[start:end], func(i int) error { return fn(start + i) }); err != nil {
+			if end < len(nodes) {
+				return fmt.Errorf("%w\nhalted with %s untouched", err, strings.Join(nodes[end:], ", "))
+			}
+			return err
+		}
+		tflog.Debug(p.ctx, fmt.Sprintf("Nodes %s done", strings.Join(nodes[start:end], ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package k3s_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+func TestNodePoolRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Bounded", func(t *testing.T) {
+		var running, most atomic.Int32
+		nodes := []string{"a", "b", "c", "d", "e", "f", "g"}
+		err := k3s.NewNodePool(t.Context(), 3).Run(nodes, func(int) error {
+			now := running.Add(1)
+			defer running.Add(-1)
+			for {
+				previous := most.Load()
+				if now <= previous || most.CompareAndSwap(previous, now) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Run shouldn't raise, got %s", err.Error())
+		}
+		if most.Load() > 3 {
+			t.Errorf("expected at most 3 nodes at once, got %d", most.Load())
+		}
+	})
+
+	t.Run("Errors per node", func(t *testing.T) {
+		var ran atomic.Int32
+		nodes := []string{"a", "b", "c"}
+		err := k3s.NewNodePool(t.Context(), 0).Run(nodes, func(i int) error {
+			ran.Add(1)
+			if nodes[i] == "a" || nodes[i] == "c" {
+				return fmt.Errorf("boom")
+			}
+			return nil
+		})
+		if ran.Load() != 3 {
+			t.Errorf("every node should run, ran %d", ran.Load())
+		}
+		var nodeErr *k3s.NodeError
+		if !errors.As(err, &nodeErr) {
+			t.Fatalf("expected a NodeError, got %v", err)
+		}
+		if err.Error() != "a: boom\nc: boom" {
+			t.Errorf("unexpected error %q", err.Error())
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		err := k3s.NewNodePool(ctx, 1).Run([]string{"a", "b"}, func(int) error { return nil })
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected nodes to be cancelled, got %v", err)
+		}
+	})
+}
+
+func TestNodePoolRolling(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Halts", func(t *testing.T) {
+		var mu sync.Mutex
+		var ran []string
+		nodes := []string{"a", "b", "c", "d", "e"}
+		err := k3s.NewNodePool(t.Context(), 0).Rolling(nodes, 2, func(i int) error {
+			mu.Lock()
+			ran = append(ran, nodes[i])
+			mu.Unlock()
+			if nodes[i] == "c" {
+				return fmt.Errorf("not ready")
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "c: not ready\nhalted with e untouched" {
+			t.Errorf("unexpected error %v", err)
+		}
+		if len(ran) != 4 || slices.Contains(ran, "e") {
+			t.Errorf("expected the batch after the failure to be untouched, ran %v", ran)
+		}
+	})
+}
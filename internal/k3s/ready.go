@@ -0,0 +1,173 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+// Interval between readiness checks.
+const readyPollInterval = 5 * time.Second
+
+type readyCheck func(ctx context.Context, clientset kubernetes.Interface) error
+
+// WaitForReady implements ComponentWaitForReady. Besides the node, waits for
+// the api server and the kube-system deployments of the packaged addons.
+func (s *server) WaitForReady(client ssh_client.SSHClient, kubeconfig string, timeout time.Duration) error {
+	name, err := nodeName(s.config, client)
+	if err != nil {
+		return err
+	}
+
+	return waitForReady(s.ctx, kubeconfig, timeout, func(ctx context.Context, clientset kubernetes.Interface) error {
+		if _, err := clientset.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx); err != nil {
+			return fmt.Errorf("api server is not ready: %s", err.Error())
+		}
+		if err := NodeReady(ctx, clientset, name); err != nil {
+			return err
+		}
+		return AddonsReady(ctx, clientset, enabledAddons(s.config))
+	})
+}
+
+// WaitForReady implements ComponentWaitForReady.
+func (a *agent) WaitForReady(client ssh_client.SSHClient, kubeconfig string, timeout time.Duration) error {
+	name, err := nodeName(a.config, client)
+	if err != nil {
+		return err
+	}
+
+	return waitForReady(a.ctx, kubeconfig, timeout, func(ctx context.Context, clientset kubernetes.Interface) error {
+		return NodeReady(ctx, clientset, name)
+	})
+}
+
+// Runs check until it passes or the timeout is hit, returning the last failure.
+func waitForReady(ctx context.Context, kubeconfig string, timeout time.Duration, check readyCheck) error {
+	clientset, err := newClientset(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		err := check(ctx, clientset)
+		if err == nil {
+			return nil
+		}
+		tflog.Info(ctx, fmt.Sprintf("Waiting for node to be ready: %s", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready after %s: %s", timeout, err.Error())
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// Errors unless the node has a true Ready condition.
+func NodeReady(ctx context.Context, clientset kubernetes.Interface, name string) error {
+	node, err := clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("getting node %s: %s", name, err.Error())
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			return nil
+		}
+	}
+	return fmt.Errorf("node %s is not Ready", name)
+}
+
+// Deployments of the addons k3s packages, by the `disable` name turning
+// each of them off.
+var packagedAddons = map[string]string{
+	"coredns":        "coredns",
+	"local-storage":  "local-path-provisioner",
+	"metrics-server": "metrics-server",
+	"traefik":        "traefik",
+}
+
+// Deployments of the packaged addons the config doesn't disable.
+func enabledAddons(config map[any]any) []string {
+	var addons []string
+	for component, deployment := range packagedAddons {
+		if !disabled(config, component) {
+			addons = append(addons, deployment)
+		}
+	}
+	return addons
+}
+
+// Errors unless each of the packaged addon deployments in kube-system, by
+// name, has all of its replicas available. Other deployments there, such as
+// ones the user installed, aren't waited for. k3s applies the addon manifests
+// after starting, so coredns must exist when it's one of the addons, while
+// the rest may be deployed later through the helm controller.
+func AddonsReady(ctx context.Context, clientset kubernetes.Interface, addons []string) error {
+	deployments, err := clientset.AppsV1().Deployments("kube-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing kube-system deployments: %s", err.Error())
+	}
+
+	found := false
+	for _, deployment := range deployments.Items {
+		if !slices.Contains(addons, deployment.Name) {
+			continue
+		}
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		if deployment.Status.AvailableReplicas < replicas {
+			return fmt.Errorf("deployment %s has %d/%d available replicas", deployment.Name, deployment.Status.AvailableReplicas, replicas)
+		}
+		found = found || deployment.Name == "coredns"
+	}
+
+	if slices.Contains(addons, "coredns") && !found {
+		return fmt.Errorf("deployment coredns does not exist yet")
+	}
+	return nil
+}
+
+// The name the node registers with, which k3s takes from the
+// `node-name` flag and otherwise the hostname.
+func nodeName(config map[any]any, client ssh_client.SSHHostname) (string, error) {
+	if name, ok := config["node-name"].(string); ok && name != "" {
+		return name, nil
+	}
+
+	hostname, err := client.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("fetching hostname: %s", err.Error())
+	}
+	return strings.ToLower(hostname), nil
+}
+
+// Whether a packaged component is turned off through `disable`.
+func disabled(config map[any]any, component string) bool {
+	switch value := config["disable"].(type) {
+	case string:
+		return slices.Contains(strings.Split(value, ","), component)
+	case []string:
+		return slices.Contains(value, component)
+	case []any:
+		for _, v := range value {
+			if v == component {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package k3s_test
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+func TestNodeReady(t *testing.T) {
+	t.Parallel()
+
+	node := func(name string, status corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: status},
+			}},
+		}
+	}
+	clientset := fake.NewClientset(node("ready", corev1.ConditionTrue), node("not-ready", corev1.ConditionFalse))
+
+	if err := k3s.NodeReady(t.Context(), clientset, "ready"); err != nil {
+		t.Errorf("Expected ready node to pass, got %s", err.Error())
+	}
+	if err := k3s.NodeReady(t.Context(), clientset, "not-ready"); err == nil {
+		t.Errorf("Expected not ready node to fail")
+	}
+	if err := k3s.NodeReady(t.Context(), clientset, "missing"); err == nil {
+		t.Errorf("Expected missing node to fail")
+	}
+}
+
+func TestAddonsReady(t *testing.T) {
+	t.Parallel()
+
+	deployment := func(name string, available int32) *appsv1.Deployment {
+		replicas := int32(1)
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: available},
+		}
+	}
+
+	addons := []string{"coredns", "metrics-server"}
+	for _, tc := range []struct {
+		name        string
+		deployments []*appsv1.Deployment
+		addons      []string
+		ok          bool
+	}{
+		{"available", []*appsv1.Deployment{deployment("coredns", 1), deployment("metrics-server", 1)}, addons, true},
+		{"unavailable addon", []*appsv1.Deployment{deployment("coredns", 1), deployment("metrics-server", 0)}, addons, false},
+		{"coredns missing", []*appsv1.Deployment{deployment("metrics-server", 1)}, addons, false},
+		{"coredns disabled", []*appsv1.Deployment{deployment("metrics-server", 1)}, []string{"metrics-server"}, true},
+		{"user deployment", []*appsv1.Deployment{deployment("coredns", 1), deployment("my-operator", 0)}, addons, true},
+		{"disabled addon", []*appsv1.Deployment{deployment("coredns", 1), deployment("metrics-server", 0)}, []string{"coredns"}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewClientset()
+			for _, d := range tc.deployments {
+				if _, err := clientset.AppsV1().Deployments("kube-system").Create(t.Context(), d, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err.Error())
+				}
+			}
+
+			err := k3s.AddonsReady(t.Context(), clientset, tc.addons)
+			if tc.ok && err != nil {
+				t.Errorf("Expected addons to be ready, got %s", err.Error())
+			}
+			if !tc.ok && err == nil {
+				t.Errorf("Expected addons not to be ready")
+			}
+		})
+	}
+}
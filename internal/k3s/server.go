@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -37,6 +38,19 @@ type ServerRegistry interface {
 type ServerHaMode interface {
 	// Adds highly avail config to the node
 	AddHA(cluster_init bool, token string, server string)
+	// Adds a separate token only usable for joining agents
+	AddAgentToken(token string)
+}
+
+type ServerAgentToken interface {
+	// The agent token used to join agents to the cluster.
+	// Running `Resync` first will ensure this is set.
+	AgentToken() string
+}
+
+type ServerDatastore interface {
+	// Adds external datastore config to the node
+	AddDatastore(endpoint string, ca string, cert string, key string)
 }
 
 type Server interface {
@@ -45,7 +59,12 @@ type Server interface {
 	ServerOidc
 	ServerConfig
 	ServerHaMode
+	ServerDatastore
 	ServerRegistry
+	ServerEtcdSnapshots
+	ServerAgentToken
+	ServerTokens
+	ServerCertificates
 }
 
 var _ Server = &server{}
@@ -54,6 +73,7 @@ type server struct {
 	config     map[any]any
 	registry   map[any]any
 	token      string
+	agentToken string
 	kubeConfig string
 	version    string
 	ctx        context.Context
@@ -72,6 +92,11 @@ func (s *server) Token() string {
 	return s.token
 }
 
+// AgentToken implements K3sServer.
+func (s *server) AgentToken() string {
+	return s.agentToken
+}
+
 // Token implements K3sServer.
 func (s *server) Config() map[any]any {
 	return s.config
@@ -95,6 +120,13 @@ func (s *server) AddHA(cluster_init bool, token string, server string) {
 	}
 }
 
+func (s *server) AddAgentToken(token string) {
+	if token != "" {
+		s.agentToken = token
+		s.config["agent-token"] = token
+	}
+}
+
 // Easy constructor for using just uninstall and resync.
 func NewK3ServerUninstall(ctx context.Context, binDir string) Server {
 	return &server{ctx: ctx, binDir: binDir}
@@ -123,27 +155,56 @@ func NewK3sServerComponent(ctx context.Context, config string, registry string,
 }
 
 func (s *server) AddOidc(audience string, issuer string, pkcs8_key string, signing_key string) {
+	pkcs8Path := fmt.Sprintf("%s/tls/sa-signer-pkcs8.pub", CONFIG_DIR)
+	signingPath := fmt.Sprintf("%s/tls/sa-signer.key", CONFIG_DIR)
 	kube_api_server_args := []string{
 		fmt.Sprintf("api-audiences=%s", audience),
-		"service-account-key-file=/etc/rancher/k3s/tls/sa-signer-pkcs8.pub",
-		"service-account-key-file=/var/lib/rancher/k3s/server/tls/service.key",
-		"service-account-signing-key-file=/etc/rancher/k3s/tls/sa-signer.key",
+		fmt.Sprintf("service-account-key-file=%s", pkcs8Path),
+		fmt.Sprintf("service-account-key-file=%s/server/tls/service.key", s.dataDir()),
+		fmt.Sprintf("service-account-signing-key-file=%s", signingPath),
 		fmt.Sprintf("service-account-issuer=%s", issuer),
 		"service-account-issuer=k3s",
 	}
 
 	api_server_args, ok := s.config["kube-apiserver-arg"]
 	if ok {
-		as_string_array, ok := api_server_args.([]string)
-		if ok {
-			s.config["kube-apiserver-arg"] = append(as_string_array, kube_api_server_args...)
+		switch existing := api_server_args.(type) {
+		case []string:
+			s.config["kube-apiserver-arg"] = append(existing, kube_api_server_args...)
+		// Args decoded from yaml
+		case []any:
+			for _, arg := range kube_api_server_args {
+				existing = append(existing, arg)
+			}
+			s.config["kube-apiserver-arg"] = existing
 		}
 	} else {
 		s.config["kube-apiserver-arg"] = kube_api_server_args
 	}
 
-	s.addFile("/etc/rancher/k3s/tls/sa-signer-pkcs8.pub", pkcs8_key)
-	s.addFile("/etc/rancher/k3s/tls/sa-signer.key", signing_key)
+	s.addFile(pkcs8Path, pkcs8_key)
+	s.addFile(signingPath, signing_key)
+}
+
+func (s *server) AddDatastore(endpoint string, ca string, cert string, key string) {
+	s.config["datastore-endpoint"] = endpoint
+
+	files := []struct {
+		key     string
+		path    string
+		content string
+	}{
+		{"datastore-cafile", fmt.Sprintf("%s/tls/datastore-ca.crt", CONFIG_DIR), ca},
+		{"datastore-certfile", fmt.Sprintf("%s/tls/datastore.crt", CONFIG_DIR), cert},
+		{"datastore-keyfile", fmt.Sprintf("%s/tls/datastore.key", CONFIG_DIR), key},
+	}
+	for _, file := range files {
+		if file.content == "" {
+			continue
+		}
+		s.config[file.key] = file.path
+		s.addFile(file.path, file.content)
+	}
 }
 
 func (s *server) addFile(path string, content string) {
@@ -151,10 +212,17 @@ func (s *server) addFile(path string, content string) {
 }
 
 func (s *server) dataDir() string {
-	if dir, ok := s.config["data_dir"].(string); ok && dir != "" {
-		return dir
+	return dataDir(s.config)
+}
+
+// Loads the remote config when this server was created without one, so
+// remote paths resolve against the node's data dir.
+func (s *server) syncConfig(client ssh_client.SSHClient) (err error) {
+	if s.config != nil {
+		return nil
 	}
-	return DATA_DIR
+	s.config, err = getConfig(client)
+	return
 }
 
 // Preinstall implements K3sComponent.
@@ -167,7 +235,7 @@ func (s *server) Preinstall(client ssh_client.SSHClient) error {
 	if err != nil {
 		return err
 	}
-	regCommands, err := registryCommands(s.ctx, s.registry)
+	regCommands, err := registryCommands(s.ctx, s.registry, registryPath(s.config))
 	if err != nil {
 		return err
 	}
@@ -234,6 +302,13 @@ func (s *server) Install(client ssh_client.SSHClient) (err error) {
 		}
 	}
 
+	if s.agentToken == "" {
+		s.agentToken, err = s.getAgentToken(client)
+		if err != nil {
+			return
+		}
+	}
+
 	// Retrieve kubeconfig
 	s.kubeConfig, err = s.getKubeConfig(client)
 
@@ -302,7 +377,7 @@ func (s *server) Update(client ssh_client.SSHClient) error {
 	if err != nil {
 		return err
 	}
-	regCommands, err := registryCommands(s.ctx, s.registry)
+	regCommands, err := registryCommands(s.ctx, s.registry, registryPath(s.config))
 	if err != nil {
 		return err
 	}
@@ -315,6 +390,11 @@ func (s *server) Update(client ssh_client.SSHClient) error {
 }
 
 func (s *server) Resync(client ssh_client.SSHClient) (err error) {
+	// Read config first, token and kubeconfig locations depend on it
+	s.config, err = getConfig(client)
+	if err != nil {
+		return
+	}
 
 	if s.token == "" {
 		s.token, err = s.getToken(client)
@@ -323,17 +403,19 @@ func (s *server) Resync(client ssh_client.SSHClient) (err error) {
 		}
 	}
 
-	s.kubeConfig, err = s.getKubeConfig(client)
-	if err != nil {
-		return
+	if s.agentToken == "" {
+		s.agentToken, err = s.getAgentToken(client)
+		if err != nil {
+			return
+		}
 	}
 
-	s.registry, err = getRegistry(client)
+	s.kubeConfig, err = s.getKubeConfig(client)
 	if err != nil {
 		return
 	}
 
-	s.config, err = getConfig(client)
+	s.registry, err = getRegistry(client, registryPath(s.config))
 
 	return
 }
@@ -341,7 +423,7 @@ func (s *server) Resync(client ssh_client.SSHClient) (err error) {
 // Retrieve server token.
 func (s *server) getToken(client ssh_client.SSHClient) (string, error) {
 	// Look in default location
-	token, err := client.ReadFile("/var/lib/rancher/k3s/server/token", true, true)
+	token, err := client.ReadFile(fmt.Sprintf("%s/server/token", s.dataDir()), true, true)
 	if err != nil {
 		return "", err
 	}
@@ -360,9 +442,25 @@ func (s *server) getToken(client ssh_client.SSHClient) (string, error) {
 	return token, nil
 }
 
+// Retrieve agent token, k3s falls back to the server token when no
+// separate agent token is configured.
+func (s *server) getAgentToken(client ssh_client.SSHClient) (string, error) {
+	token, err := client.ReadFile(fmt.Sprintf("%s/server/agent-token", s.dataDir()), true, true)
+	if err != nil {
+		return "", err
+	}
+
+	token = strings.Trim(token, "\n")
+	if token == "" {
+		return s.token, nil
+	}
+	tflog.MaskMessageStrings(s.ctx, token)
+	return token, nil
+}
+
 // Retrieve kubeconfig.
 func (s *server) getKubeConfig(client ssh_client.SSHClient) (string, error) {
-	kubeconfig, err := client.ReadFile("/etc/rancher/k3s/k3s.yaml", false, true)
+	kubeconfig, err := client.ReadFile(kubeconfigPath(s.config), false, true)
 	if err != nil {
 		return "", fmt.Errorf("could not retrieve kubeconfig: %s", err.Error())
 	}
@@ -381,9 +479,17 @@ func updateKubeConfig(kubeconfigText string, host string) (string, error) {
 		return "", err
 	}
 
-	this := *config.Clusters["default"]
-	this.Server = fmt.Sprintf("https://%s:6443", strings.ReplaceAll(host, ":22", ""))
-	config.Clusters["default"] = &this
+	cluster, _, err := CurrentKubeConfigEntries(config)
+	if err != nil {
+		return "", err
+	}
+	// Host comes with the ssh port
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	if cluster.Server, err = ReplaceServerHost(cluster.Server, host); err != nil {
+		return "", err
+	}
 
 	fixed, err := clientcmd.Write(*config)
 	if err != nil {
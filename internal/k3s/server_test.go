@@ -1,6 +1,7 @@
 package k3s_test
 
 import (
+	"slices"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -50,3 +51,33 @@ write-kubeconfig-mode: "0700"`,
 	}
 
 }
+
+func TestAddOidcToExistingArgs(t *testing.T) {
+	t.Parallel()
+
+	server, err := k3s.NewK3sServerComponent(t.Context(), `kube-apiserver-arg: [v=2]`, "", "", "")
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	server.AddOidc("audience", "https://issuer", "", "")
+
+	args, ok := server.Config()["kube-apiserver-arg"].([]any)
+	if !ok || len(args) != 7 || args[0] != "v=2" {
+		t.Fatalf("Expected oidc args appended to the existing args, got %v", server.Config()["kube-apiserver-arg"])
+	}
+}
+
+func TestAddOidcWithDataDir(t *testing.T) {
+	t.Parallel()
+
+	server, err := k3s.NewK3sServerComponent(t.Context(), `data-dir: /opt/k3s`, "", "", "")
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	server.AddOidc("audience", "https://issuer", "", "")
+
+	args, _ := server.Config()["kube-apiserver-arg"].([]string)
+	if !slices.Contains(args, "service-account-key-file=/opt/k3s/server/tls/service.key") {
+		t.Fatalf("Expected service key under the configured data dir, got %v", args)
+	}
+}
@@ -0,0 +1,203 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Marks the objects created for a service account kubeconfig.
+const managedByLabel = "app.kubernetes.io/managed-by"
+const managedBy = "terraform-provider-k3s"
+
+type ServiceAccount struct {
+	Name      string
+	Namespace string
+	// Role bound to the service account
+	Role string
+	// Either ClusterRole or Role, a Role can only be bound in its namespace
+	RoleKind string
+	// Bind with a ClusterRoleBinding instead of a RoleBinding in the namespace
+	ClusterWide bool
+}
+
+type ServiceAccounts interface {
+	// Creates the service account and binds it to its role. Objects already
+	// there are taken over only when they carry the managed-by label, and
+	// bindings only when they bind the same role to the same service account.
+	CreateServiceAccount(account ServiceAccount) error
+	// Requests a token bound to the service account through the TokenRequest
+	// api, returning it with its expiry
+	RequestToken(account ServiceAccount, ttl time.Duration) (string, time.Time, error)
+	// Whether the service account still exists
+	ServiceAccountExists(account ServiceAccount) (bool, error)
+	// Deletes the role binding and the service account, which revokes every
+	// token issued for it
+	DeleteServiceAccount(account ServiceAccount) error
+}
+
+var _ ServiceAccounts = &serviceAccounts{}
+
+type serviceAccounts struct {
+	ctx       context.Context
+	clientset kubernetes.Interface
+}
+
+// Manages service accounts through the api server of the kubeconfig,
+// usually the admin kubeconfig of a k3s server.
+func NewServiceAccounts(ctx context.Context, kubeconfig string) (ServiceAccounts, error) {
+	clientset, err := newClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewServiceAccountsForClientset(ctx, clientset), nil
+}
+
+func NewServiceAccountsForClientset(ctx context.Context, clientset kubernetes.Interface) ServiceAccounts {
+	return &serviceAccounts{ctx: ctx, clientset: clientset}
+}
+
+func objectMeta(account ServiceAccount, namespaced bool) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{
+		Name:   account.Name,
+		Labels: map[string]string{managedByLabel: managedBy},
+	}
+	if namespaced {
+		meta.Namespace = account.Namespace
+	}
+	return meta
+}
+
+func managed(meta metav1.ObjectMeta) bool {
+	return meta.Labels[managedByLabel] == managedBy
+}
+
+// A binding already there is only taken over when it was created by the
+// provider for the same role and service account, never someone else's.
+func adoptBinding(meta metav1.ObjectMeta, existingRef rbacv1.RoleRef, existingSubjects []rbacv1.Subject, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) error {
+	if !managed(meta) {
+		return fmt.Errorf("role binding %s already exists and isn't managed by %s", meta.Name, managedBy)
+	}
+	if existingRef != roleRef || !slices.Equal(existingSubjects, subjects) {
+		return fmt.Errorf("role binding %s already exists for another role or subjects", meta.Name)
+	}
+	return nil
+}
+
+// CreateServiceAccount implements ServiceAccounts.
+func (s *serviceAccounts) CreateServiceAccount(account ServiceAccount) error {
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: objectMeta(account, true)}
+	_, err := s.clientset.CoreV1().ServiceAccounts(account.Namespace).Create(s.ctx, serviceAccount, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		existing, getErr := s.clientset.CoreV1().ServiceAccounts(account.Namespace).Get(s.ctx, account.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return fmt.Errorf("getting service account %s/%s: %s", account.Namespace, account.Name, getErr.Error())
+		}
+		if !managed(existing.ObjectMeta) {
+			return fmt.Errorf("service account %s/%s already exists and isn't managed by %s", account.Namespace, account.Name, managedBy)
+		}
+	} else if err != nil {
+		return fmt.Errorf("creating service account %s/%s: %s", account.Namespace, account.Name, err.Error())
+	}
+	tflog.Debug(s.ctx, fmt.Sprintf("Service account %s/%s created", account.Namespace, account.Name))
+
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      account.Name,
+		Namespace: account.Namespace,
+	}}
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     account.RoleKind,
+		Name:     account.Role,
+	}
+
+	if account.ClusterWide {
+		binding := &rbacv1.ClusterRoleBinding{ObjectMeta: objectMeta(account, false), Subjects: subjects, RoleRef: roleRef}
+		_, err = s.clientset.RbacV1().ClusterRoleBindings().Create(s.ctx, binding, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			if existing, getErr := s.clientset.RbacV1().ClusterRoleBindings().Get(s.ctx, account.Name, metav1.GetOptions{}); getErr != nil {
+				err = getErr
+			} else {
+				err = adoptBinding(existing.ObjectMeta, existing.RoleRef, existing.Subjects, roleRef, subjects)
+			}
+		}
+	} else {
+		binding := &rbacv1.RoleBinding{ObjectMeta: objectMeta(account, true), Subjects: subjects, RoleRef: roleRef}
+		_, err = s.clientset.RbacV1().RoleBindings(account.Namespace).Create(s.ctx, binding, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			if existing, getErr := s.clientset.RbacV1().RoleBindings(account.Namespace).Get(s.ctx, account.Name, metav1.GetOptions{}); getErr != nil {
+				err = getErr
+			} else {
+				err = adoptBinding(existing.ObjectMeta, existing.RoleRef, existing.Subjects, roleRef, subjects)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("binding service account %s/%s to %s %s: %s", account.Namespace, account.Name, account.RoleKind, account.Role, err.Error())
+	}
+	tflog.Debug(s.ctx, fmt.Sprintf("Service account %s/%s bound to %s %s", account.Namespace, account.Name, account.RoleKind, account.Role))
+
+	return nil
+}
+
+// RequestToken implements ServiceAccounts.
+func (s *serviceAccounts) RequestToken(account ServiceAccount, ttl time.Duration) (string, time.Time, error) {
+	seconds := int64(ttl.Seconds())
+	request := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &seconds},
+	}
+
+	res, err := s.clientset.CoreV1().ServiceAccounts(account.Namespace).CreateToken(s.ctx, account.Name, request, metav1.CreateOptions{})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("requesting token for %s/%s: %s", account.Namespace, account.Name, err.Error())
+	}
+	if res.Status.Token == "" {
+		return "", time.Time{}, fmt.Errorf("no token returned for %s/%s", account.Namespace, account.Name)
+	}
+
+	tflog.MaskMessageStrings(s.ctx, res.Status.Token)
+	return res.Status.Token, res.Status.ExpirationTimestamp.Time, nil
+}
+
+// ServiceAccountExists implements ServiceAccounts.
+func (s *serviceAccounts) ServiceAccountExists(account ServiceAccount) (bool, error) {
+	_, err := s.clientset.CoreV1().ServiceAccounts(account.Namespace).Get(s.ctx, account.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("getting service account %s/%s: %s", account.Namespace, account.Name, err.Error())
+	}
+	return true, nil
+}
+
+// DeleteServiceAccount implements ServiceAccounts.
+func (s *serviceAccounts) DeleteServiceAccount(account ServiceAccount) error {
+	var err error
+	if account.ClusterWide {
+		err = s.clientset.RbacV1().ClusterRoleBindings().Delete(s.ctx, account.Name, metav1.DeleteOptions{})
+	} else {
+		err = s.clientset.RbacV1().RoleBindings(account.Namespace).Delete(s.ctx, account.Name, metav1.DeleteOptions{})
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting role binding %s: %s", account.Name, err.Error())
+	}
+
+	err = s.clientset.CoreV1().ServiceAccounts(account.Namespace).Delete(s.ctx, account.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting service account %s/%s: %s", account.Namespace, account.Name, err.Error())
+	}
+	tflog.Debug(s.ctx, fmt.Sprintf("Service account %s/%s deleted", account.Namespace, account.Name))
+
+	return nil
+}
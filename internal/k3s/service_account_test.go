@@ -0,0 +1,124 @@
+package k3s_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+func TestServiceAccounts(t *testing.T) {
+	t.Parallel()
+
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		request := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		if *request.Spec.ExpirationSeconds != 3600 {
+			t.Errorf("Expected a 1h token, got %ds", *request.Spec.ExpirationSeconds)
+		}
+		request.Status = authenticationv1.TokenRequestStatus{Token: "abc123", ExpirationTimestamp: metav1.NewTime(expiry)}
+		return true, request, nil
+	})
+	accounts := k3s.NewServiceAccountsForClientset(t.Context(), clientset)
+
+	for _, account := range []k3s.ServiceAccount{
+		{Name: "ci", Namespace: "apps", Role: "edit", RoleKind: "ClusterRole"},
+		{Name: "monitoring", Namespace: "monitoring", Role: "view", RoleKind: "ClusterRole", ClusterWide: true},
+	} {
+		t.Run(account.Name, func(t *testing.T) {
+			if err := accounts.CreateServiceAccount(account); err != nil {
+				t.Fatalf("Expected nil err but found: %v", err.Error())
+			}
+			// Taken over when already there
+			if err := accounts.CreateServiceAccount(account); err != nil {
+				t.Fatalf("Expected existing objects to be taken over, got %v", err.Error())
+			}
+
+			if account.ClusterWide {
+				binding, err := clientset.RbacV1().ClusterRoleBindings().Get(t.Context(), account.Name, metav1.GetOptions{})
+				if err != nil || binding.RoleRef.Name != account.Role || binding.Subjects[0].Namespace != account.Namespace {
+					t.Errorf("Expected a cluster role binding to %s, got %v %v", account.Role, binding, err)
+				}
+			} else {
+				binding, err := clientset.RbacV1().RoleBindings(account.Namespace).Get(t.Context(), account.Name, metav1.GetOptions{})
+				if err != nil || binding.RoleRef.Name != account.Role || binding.RoleRef.Kind != account.RoleKind {
+					t.Errorf("Expected a role binding to %s, got %v %v", account.Role, binding, err)
+				}
+			}
+
+			token, expires, err := accounts.RequestToken(account, time.Hour)
+			if err != nil {
+				t.Fatalf("Expected nil err but found: %v", err.Error())
+			}
+			if token != "abc123" || !expires.Equal(expiry) {
+				t.Errorf("Unexpected token %s expiring %s", token, expires)
+			}
+
+			if exists, err := accounts.ServiceAccountExists(account); err != nil || !exists {
+				t.Errorf("Expected service account to exist, got %v %v", exists, err)
+			}
+			if err := accounts.DeleteServiceAccount(account); err != nil {
+				t.Fatalf("Expected nil err but found: %v", err.Error())
+			}
+			if exists, err := accounts.ServiceAccountExists(account); err != nil || exists {
+				t.Errorf("Expected service account to be deleted, got %v %v", exists, err)
+			}
+			// Already gone
+			if err := accounts.DeleteServiceAccount(account); err != nil {
+				t.Errorf("Expected deleting twice to pass, got %v", err.Error())
+			}
+		})
+	}
+}
+
+func TestServiceAccountsNotManaged(t *testing.T) {
+	t.Parallel()
+
+	account := k3s.ServiceAccount{Name: "ci", Namespace: "apps", Role: "edit", RoleKind: "ClusterRole"}
+
+	t.Run("Service account", func(t *testing.T) {
+		clientset := fake.NewClientset(&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci", Namespace: "apps"},
+		})
+		err := k3s.NewServiceAccountsForClientset(t.Context(), clientset).CreateServiceAccount(account)
+		if err == nil || !strings.Contains(err.Error(), "isn't managed by") {
+			t.Errorf("Expected an existing service account to be refused, got %v", err)
+		}
+	})
+
+	t.Run("Binding", func(t *testing.T) {
+		clientset := fake.NewClientset(&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci", Namespace: "apps"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+		})
+		err := k3s.NewServiceAccountsForClientset(t.Context(), clientset).CreateServiceAccount(account)
+		if err == nil || !strings.Contains(err.Error(), "isn't managed by") {
+			t.Errorf("Expected an existing role binding to be refused, got %v", err)
+		}
+	})
+
+	t.Run("Other role", func(t *testing.T) {
+		accounts := k3s.NewServiceAccountsForClientset(t.Context(), fake.NewClientset())
+		if err := accounts.CreateServiceAccount(account); err != nil {
+			t.Fatalf("Expected nil err but found: %v", err.Error())
+		}
+		admin := account
+		admin.Role = "admin"
+		err := accounts.CreateServiceAccount(admin)
+		if err == nil || !strings.Contains(err.Error(), "another role or subjects") {
+			t.Errorf("Expected a binding to another role to be refused, got %v", err)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"maps"
 	"regexp"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -18,6 +19,7 @@ import (
 
 const DATA_DIR string = "/var/lib/rancher/k3s"
 const CONFIG_DIR string = "/etc/rancher/k3s"
+const BIN_DIR string = "/usr/local/bin"
 
 type ComponentPreInstall interface {
 	// Ensures all files and configs are present on remote node.
@@ -48,6 +50,12 @@ type ComponentStatus interface {
 	Status(ssh_client.SSHClient) (bool, error)
 }
 
+type ComponentWaitForReady interface {
+	// Polls the cluster, through the kubeconfig, until the node
+	// is Ready or the timeout passes.
+	WaitForReady(client ssh_client.SSHClient, kubeconfig string, timeout time.Duration) error
+}
+
 type ComponentResync interface {
 	// Resyncs node object with remote.
 	Resync(ssh_client.SSHClient) error
@@ -65,6 +73,7 @@ type Component interface {
 	ComponentUninstall
 	ComponentUpdate
 	ComponentStatus
+	ComponentWaitForReady
 	ComponentResync
 	ComponentToken
 }
@@ -100,11 +109,10 @@ func configCommands(ctx context.Context, config map[any]any) ([]string, error) {
 	}, nil
 }
 
-// Commands for configuring server/agent registry.
-func registryCommands(ctx context.Context, registry map[any]any) (commands []string, err error) {
+// Commands for configuring server/agent registry at path.
+func registryCommands(ctx context.Context, registry map[any]any, registryPath string) (commands []string, err error) {
 	tflog.Debug(ctx, "Reading registries")
 
-	registryPath := fmt.Sprintf("%s/registries.yaml", CONFIG_DIR)
 	var registryContents []byte
 	if registry != nil {
 		registryContents, err = yaml.Marshal(registry)
@@ -144,12 +152,34 @@ func readYaml(client ssh_client.SSHClient, file string, missingOk ...bool) (map[
 }
 
 func getConfig(client ssh_client.SSHClient) (map[any]any, error) {
-	return readYaml(client, "/etc/rancher/k3s/config.yaml", true)
+	return readYaml(client, fmt.Sprintf("%s/config.yaml", CONFIG_DIR), true)
 }
 
-// Retrieve kubeconfig.
-func getRegistry(client ssh_client.SSHClient) (map[any]any, error) {
-	return readYaml(client, "/etc/rancher/k3s/registry.yaml", true)
+// Retrieve registry.
+func getRegistry(client ssh_client.SSHClient, registryPath string) (map[any]any, error) {
+	return readYaml(client, registryPath, true)
+}
+
+// Effective data dir of a k3s config.
+func dataDir(config map[any]any) string {
+	return configPath(config, "data-dir", DATA_DIR)
+}
+
+// Effective location of the admin kubeconfig of a k3s server config.
+func kubeconfigPath(config map[any]any) string {
+	return configPath(config, "write-kubeconfig", fmt.Sprintf("%s/k3s.yaml", CONFIG_DIR))
+}
+
+// Effective location of the registries file of a k3s config.
+func registryPath(config map[any]any) string {
+	return configPath(config, "private-registry", fmt.Sprintf("%s/registries.yaml", CONFIG_DIR))
+}
+
+func configPath(config map[any]any, key string, fallback string) string {
+	if path, ok := config[key].(string); ok && path != "" {
+		return path
+	}
+	return fallback
 }
 
 func deleteNode(ctx context.Context, kubeconfig string, hostname string) error {
@@ -157,28 +187,29 @@ func deleteNode(ctx context.Context, kubeconfig string, hostname string) error {
 		tflog.Warn(ctx, fmt.Sprintf("Could not gracefully delete node for: %v", hostname))
 		return nil
 	}
-	config, err := clientcmd.NewClientConfigFromBytes([]byte(kubeconfig))
+	clientset, err := newClientset(kubeconfig)
 	if err != nil {
-		tflog.Warn(ctx, fmt.Sprintf("Could not create kuberentes config: %v", err.Error()))
+		tflog.Warn(ctx, fmt.Sprintf("Could not create kuberentes api client: %v", err.Error()))
 		return err
 	}
 
-	// Create the rest.Config object
-	restConfig, err := config.ClientConfig()
+	return clientset.CoreV1().Nodes().Delete(ctx, hostname, metav1.DeleteOptions{})
+
+}
+
+// Kubernetes api client from a kubeconfig.
+func newClientset(kubeconfig string) (*kubernetes.Clientset, error) {
+	config, err := clientcmd.NewClientConfigFromBytes([]byte(kubeconfig))
 	if err != nil {
-		tflog.Warn(ctx, fmt.Sprintf("Could not create kuberentes rest client: %v", err.Error()))
-		return err
+		return nil, fmt.Errorf("creating kubernetes config: %s", err.Error())
 	}
 
-	// Create the Kubernetes clientset
-	clientset, err := kubernetes.NewForConfig(restConfig)
+	restConfig, err := config.ClientConfig()
 	if err != nil {
-		tflog.Warn(ctx, fmt.Sprintf("Could not create kuberentes api client: %v", err.Error()))
-		return err
+		return nil, fmt.Errorf("creating kubernetes rest client: %s", err.Error())
 	}
 
-	return clientset.CoreV1().Nodes().Delete(ctx, hostname, metav1.DeleteOptions{})
-
+	return kubernetes.NewForConfig(restConfig)
 }
 
 func ParseYamlString(value basetypes.StringValue, mergeWith ...basetypes.StringValue) (config map[any]any, err error) {
@@ -193,6 +224,23 @@ func ParseYamlString(value basetypes.StringValue, mergeWith ...basetypes.StringV
 	return
 }
 
+// Deep merges yaml documents, later documents taking precedence.
+func MergeYamlStrings(documents ...string) (string, error) {
+	config := map[any]any{}
+	for _, document := range documents {
+		local, err := ParseYamlString(basetypes.NewStringValue(document))
+		if err != nil {
+			return "", err
+		}
+		config = mergeMaps(config, local)
+	}
+	merged, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(merged), nil
+}
+
 func mergeMaps(a, b map[interface{}]interface{}) map[interface{}]interface{} {
 	out := make(map[interface{}]interface{}, len(a))
 	maps.Copy(out, a)
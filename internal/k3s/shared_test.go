@@ -0,0 +1,96 @@
+package k3s_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: default
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: default
+  context:
+    cluster: default
+    user: default
+current-context: default
+users:
+- name: default
+  user:
+    token: abc
+`
+
+// Remote node whose files are read from a map, missing files are empty.
+type filesSSH struct {
+	recordRun
+	files map[string]string
+}
+
+func (f *filesSSH) RunStream([]string) error    { return nil }
+func (f *filesSSH) WaitForReady() error         { return nil }
+func (f *filesSSH) Host() string                { return "10.0.0.1:22" }
+func (f *filesSSH) HostnameOrIpAddress() string { return "10.0.0.1" }
+func (f *filesSSH) Hostname() (string, error)   { return "node", nil }
+func (f *filesSSH) ReadFile(path string, _ bool, _ bool) (string, error) {
+	return f.files[path], nil
+}
+
+func TestConfigPaths(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		config     string
+		dataDir    string
+		kubeconfig string
+		registry   string
+	}{
+		{"default", "", k3s.DATA_DIR, k3s.CONFIG_DIR + "/k3s.yaml", k3s.CONFIG_DIR + "/registries.yaml"},
+		{
+			"relocated",
+			"data-dir: /opt/k3s\nwrite-kubeconfig: /root/.kube/config\nprivate-registry: /opt/registries.yaml\n",
+			"/opt/k3s", "/root/.kube/config", "/opt/registries.yaml",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &filesSSH{files: map[string]string{
+				k3s.CONFIG_DIR + "/config.yaml":    tc.config,
+				tc.dataDir + "/server/token":       "token\n",
+				tc.dataDir + "/server/agent-token": "agent-token\n",
+				tc.kubeconfig:                      testKubeconfig,
+				tc.registry:                        "mirrors: {}\n",
+			}}
+			server, err := k3s.NewK3sServerComponent(t.Context(), "", "", "", "")
+			if err != nil {
+				t.Fatalf("Expected nil err but found: %v", err.Error())
+			}
+			if err := server.Resync(client); err != nil {
+				t.Fatalf("Expected nil err but found: %v", err.Error())
+			}
+
+			if server.Token() != "token" || server.AgentToken() != "agent-token" {
+				t.Errorf("Expected tokens from %s, got %s and %s", tc.dataDir, server.Token(), server.AgentToken())
+			}
+			if !strings.Contains(server.KubeConfig(), "https://10.0.0.1:6443") {
+				t.Errorf("Expected kubeconfig from %s, got %s", tc.kubeconfig, server.KubeConfig())
+			}
+			if _, ok := server.Registry()["mirrors"]; !ok {
+				t.Errorf("Expected registry from %s, got %v", tc.registry, server.Registry())
+			}
+
+			if _, err := server.CertificateExpiry(client); err != nil {
+				t.Fatalf("Expected nil err but found: %v", err.Error())
+			}
+			tlsDir := fmt.Sprintf("%s/server/tls/*.crt", tc.dataDir)
+			if len(client.commands) != 1 || !strings.Contains(client.commands[0], tlsDir) {
+				t.Errorf("Expected certificates read from %s, got %v", tlsDir, client.commands)
+			}
+		})
+	}
+}
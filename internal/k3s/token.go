@@ -0,0 +1,150 @@
+package k3s
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joho/godotenv"
+	"striveworks.us/terraform-provider-k3s/internal/ssh_client"
+)
+
+type ServerTokens interface {
+	// Creates a bootstrap token, returning the full join token
+	CreateToken(client ssh_client.SSHRun, ttl string, description string) (string, error)
+	// Deletes a bootstrap token by id
+	DeleteToken(client ssh_client.SSHRun, id string) error
+	// Lists the ids of the bootstrap tokens known to the cluster
+	ListTokens(client ssh_client.SSHRun) ([]string, error)
+	// Rotates the server token of the cluster to `newToken`
+	RotateToken(client ssh_client.SSHClient, newToken string) error
+	// Points a server node at a new server token and restarts it
+	UpdateToken(client ssh_client.SSHClient, token string) error
+}
+
+// CreateToken implements ServerTokens.
+func (s *server) CreateToken(client ssh_client.SSHRun, ttl string, description string) (string, error) {
+	command := "sudo k3s token create"
+	if ttl != "" {
+		command = fmt.Sprintf("%s --ttl %s", command, ShellQuote(ttl))
+	}
+	if description != "" {
+		command = fmt.Sprintf("%s --description %s", command, ShellQuote(description))
+	}
+
+	res, err := client.Run(command)
+	if err != nil {
+		return "", fmt.Errorf("creating token: %s", err.Error())
+	}
+
+	if len(res) != 1 {
+		return "", fmt.Errorf("wrong number of results from token create")
+	}
+
+	token := strings.TrimSpace(res[0])
+	tflog.MaskMessageStrings(s.ctx, token)
+	return token, nil
+}
+
+// DeleteToken implements ServerTokens.
+func (s *server) DeleteToken(client ssh_client.SSHRun, id string) error {
+	if _, err := client.Run(fmt.Sprintf("sudo k3s token delete %s", ShellQuote(id))); err != nil {
+		return fmt.Errorf("deleting token: %s", err.Error())
+	}
+	return nil
+}
+
+// ListTokens implements ServerTokens.
+func (s *server) ListTokens(client ssh_client.SSHRun) ([]string, error) {
+	res, err := client.Run("sudo k3s token list")
+	if err != nil {
+		return nil, fmt.Errorf("listing tokens: %s", err.Error())
+	}
+
+	if len(res) != 1 {
+		return nil, fmt.Errorf("wrong number of results from token list")
+	}
+
+	return parseTokenList(res[0]), nil
+}
+
+// RotateToken implements ServerTokens.
+func (s *server) RotateToken(client ssh_client.SSHClient, newToken string) error {
+	if err := s.syncConfig(client); err != nil {
+		return err
+	}
+
+	oldToken, err := s.getToken(client)
+	if err != nil {
+		return err
+	}
+	tflog.MaskMessageStrings(s.ctx, newToken)
+
+	_, err = client.Run(fmt.Sprintf("sudo k3s token rotate --token %s --new-token %s", ShellQuote(oldToken), ShellQuote(newToken)))
+	if err != nil {
+		return fmt.Errorf("rotating token: %s", err.Error())
+	}
+
+	s.token = newToken
+	return nil
+}
+
+// UpdateToken implements ServerTokens.
+func (s *server) UpdateToken(client ssh_client.SSHClient, token string) error {
+	config, err := getConfig(client)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		config = make(map[any]any)
+	}
+	config["token"] = token
+
+	commands, err := configCommands(s.ctx, config)
+	if err != nil {
+		return err
+	}
+
+	// The installer persists the join token to the service env, which takes precedence over config
+	env, err := s.getServerEnv(client)
+	if err != nil {
+		return err
+	}
+	if _, ok := env["K3S_TOKEN"]; ok {
+		env["K3S_TOKEN"] = token
+		contents, err := godotenv.Marshal(env)
+		if err != nil {
+			return err
+		}
+		commands = append(commands, WriteFileCommands("/etc/systemd/system/k3s.service.env", base64.StdEncoding.EncodeToString([]byte(contents+"\n")))...)
+	}
+
+	s.token = token
+	return client.RunStream(append(commands, "sudo systemctl daemon-reload", "sudo systemctl restart k3s"))
+}
+
+// The id of a bootstrap token, which is the first half of `id.secret`
+// with any `K10<ca-hash>::` prefix removed.
+func TokenId(token string) string {
+	if idx := strings.LastIndex(token, "::"); idx >= 0 {
+		token = token[idx+2:]
+	}
+	id, _, _ := strings.Cut(token, ".")
+	return id
+}
+
+// Parses the ids out of `k3s token list`, which is a table with the id in the first column.
+func parseTokenList(output string) []string {
+	ids := []string{}
+	idPattern := regexp.MustCompile(`^[a-z0-9]{6}$`)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !idPattern.MatchString(fields[0]) {
+			continue
+		}
+		ids = append(ids, fields[0])
+	}
+	return ids
+}
@@ -0,0 +1,68 @@
+package k3s_test
+
+import (
+	"reflect"
+	"testing"
+
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+type recordRun struct {
+	output   string
+	commands []string
+}
+
+func (r *recordRun) Run(commands ...string) ([]string, error) {
+	r.commands = append(r.commands, commands...)
+	return []string{r.output}, nil
+}
+
+func TestTokenId(t *testing.T) {
+	t.Parallel()
+
+	for token, expected := range map[string]string{
+		"K10d3f1c2::abcdef.0123456789abcdef": "abcdef",
+		"abcdef.0123456789abcdef":            "abcdef",
+	} {
+		if got := k3s.TokenId(token); got != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, token, got)
+		}
+	}
+}
+
+func TestListTokens(t *testing.T) {
+	t.Parallel()
+
+	server, err := k3s.NewK3sServerComponent(t.Context(), "", "", "", "")
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	ids, err := server.ListTokens(&recordRun{output: `TOKEN     TTL         EXPIRES                USAGES                   DESCRIPTION   EXTRA GROUPS
+abcdef    23h         2025-05-26T15:05:44Z   authentication,signing   ci            system:bootstrappers:k3s:default-node-token
+123456    <forever>   <never>                authentication,signing   <none>        system:bootstrappers:k3s:default-node-token
+`})
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	expected := []string{"abcdef", "123456"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+}
+
+func TestCreateTokenQuoting(t *testing.T) {
+	t.Parallel()
+
+	server, err := k3s.NewK3sServerComponent(t.Context(), "", "", "", "")
+	if err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	client := &recordRun{output: "K10abc::abcdef.0123456789abcdef\n"}
+	if _, err := server.CreateToken(client, "1h", "ci'; rm -rf / #"); err != nil {
+		t.Fatalf("Expected nil err but found: %v", err.Error())
+	}
+	expected := `sudo k3s token create --ttl '1h' --description 'ci'\''; rm -rf / #'`
+	if len(client.commands) != 1 || client.commands[0] != expected {
+		t.Errorf("Expected %s, got %v", expected, client.commands)
+	}
+}
@@ -2,13 +2,17 @@ package provider
 
 import (
 	"context"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"striveworks.us/terraform-provider-k3s/internal/handlers"
 	"striveworks.us/terraform-provider-k3s/internal/k3s"
@@ -16,15 +20,19 @@ import (
 
 var _ resource.ResourceWithConfigure = &K3sAgentResource{}
 var _ resource.ResourceWithConfigValidators = &K3sAgentResource{}
+var _ resource.ResourceWithImportState = &K3sAgentResource{}
 
 type K3sAgentResource struct {
-	version *string
+	version  *string
+	defaults *handlers.DefaultAuth
 }
 
 // Schema implements resource.Resource.
 func (k *K3sAgentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Creates a k3s agent resource. Only one of `password` or `private_key` can be passed. Requires a token and server address to a k3s_server resource",
+		Version: 1,
+		MarkdownDescription: ("Creates a k3s agent resource. Only one of `password` or `private_key` can be passed. " +
+			"Requires a token, or agent token, and server address to a k3s_server resource"),
 
 		Attributes: map[string]schema.Attribute{
 			// Inputs
@@ -35,24 +43,53 @@ func (k *K3sAgentResource) Schema(ctx context.Context, req resource.SchemaReques
 				Computed:            true,
 			},
 			// Auth
-			"auth": handlers.NodeAuth{}.Schema(),
+			"auth":            handlers.NodeAuth{}.Schema(),
+			"auth_wo":         handlers.AuthWriteOnly{}.Schema(),
+			"auth_wo_version": handlers.WriteOnlyVersionSchema("auth_wo"),
 			// Config
 			"config": schema.StringAttribute{
 				Optional:            true,
+				Computed:            true,
+				CustomType:          handlers.YamlType{},
 				MarkdownDescription: "K3s server config",
+				PlanModifiers: []planmodifier.String{
+					handlers.YamlUseStateWhenEqual(),
+				},
 			},
+			"agent_config": handlers.AgentConfig{}.Schema(),
 			"registry": schema.StringAttribute{
 				Optional:            true,
+				Computed:            true,
+				CustomType:          handlers.YamlType{},
 				MarkdownDescription: "K3s agent registry",
+				PlanModifiers: []planmodifier.String{
+					handlers.YamlUseStateWhenEqual(),
+				},
 			},
+			"wait_for_ready": handlers.WaitForReady{}.CreateOnlySchema(),
 			"allow_delete_err": schema.BoolAttribute{
 				Optional:            true,
 				MarkdownDescription: "If this is true, deleting the node using kubectl first will be allowed to error not stopping the k3s uninstall process",
 			},
 			"token": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Server token used for joining nodes to the cluster. Only one of `token`, `agent_token` or `token_wo` can be passed",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"token_wo": schema.StringAttribute{
+				Optional:            true,
 				Sensitive:           true,
-				MarkdownDescription: "Server token used for joining nodes to the cluster",
+				WriteOnly:           true,
+				MarkdownDescription: "Write-only variant of `token` or `agent_token`, never stored in state",
+			},
+			"token_wo_version": handlers.WriteOnlyVersionSchema("token_wo", int64planmodifier.RequiresReplace()),
+			"agent_token": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Agent token used for joining the node to the cluster without server level trust",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -84,6 +121,9 @@ func (k *K3sAgentResource) Schema(ctx context.Context, req resource.SchemaReques
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": handlers.TimeoutsSchema(ctx),
+		},
 	}
 }
 
@@ -101,20 +141,33 @@ func (k *K3sAgentResource) Configure(ctx context.Context, req resource.Configure
 	if provider.Version != "" {
 		k.version = &provider.Version
 	}
+	k.defaults = provider.DefaultAuth
 }
 
 // Create implements resource.Resource.
 func (k *K3sAgentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data handlers.AgentClientModel
+	var config handlers.AgentClientModel
 
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	data.SetWriteOnly(config)
 	data.SetVersion(k.version)
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	createTimeout, diags := data.Timeouts.Create(ctx, handlers.DefaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithWriteOnly(ctx, data.AuthWo).WithDefaults(k.defaults)
+	data.Auth = auth.StateObject(ctx)
 	agent, err := data.ToAgent(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("creating k3s agent", err.Error())
@@ -142,7 +195,15 @@ func (k *K3sAgentResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, handlers.DefaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
 	agent := k3s.NewK3sAgentUninstall(ctx, data.BinDir.ValueString())
 
 	if err := data.Delete(ctx, &auth, agent); err != nil {
@@ -167,7 +228,15 @@ func (k *K3sAgentResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	readTimeout, diags := data.Timeouts.Read(ctx, handlers.DefaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
 	agent, err := data.ToAgent(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("building k3s agent", err.Error())
@@ -182,6 +251,33 @@ func (k *K3sAgentResource) Read(ctx context.Context, req resource.ReadRequest, r
 	resp.Diagnostics.Append(req.State.Set(ctx, &data)...)
 }
 
+// ImportState implements resource.ResourceWithImportState.
+func (k *K3sAgentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	ctx, cancel := context.WithTimeout(ctx, handlers.DefaultReadTimeout)
+	defer cancel()
+
+	auth, err := handlers.NewImportNodeAuth(req.ID, "agent", k.defaults)
+	if err != nil {
+		resp.Diagnostics.AddError("importing k3s agent", err.Error())
+		return
+	}
+
+	data := handlers.AgentClientModel{Auth: auth.ToObject(ctx)}
+	agent, err := data.ToAgent(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("importing k3s agent", err.Error())
+		return
+	}
+
+	if err := data.Import(ctx, &auth, agent); err != nil {
+		resp.Diagnostics.AddError("importing k3s agent", err.Error())
+		return
+	}
+
+	tflog.Info(ctx, "imported a k3s agent resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
 // Update implements resource.Resource.
 func (k *K3sAgentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data handlers.AgentClientModel
@@ -194,7 +290,16 @@ func (k *K3sAgentResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	updateTimeout, diags := data.Timeouts.Update(ctx, handlers.DefaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithWriteOnly(ctx, data.AuthWo).WithDefaults(k.defaults)
+	data.Auth = auth.StateObject(ctx)
 	agent, err := data.ToAgent(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("building k3s agent", err.Error())
@@ -206,6 +311,11 @@ func (k *K3sAgentResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	// Only applies on create, kept in line with the plan
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("wait_for_ready"), &state.WaitForReady)...)
+	state.Auth = data.Auth
+	state.AuthWoVersion = data.AuthWoVersion
+	state.Timeouts = data.Timeouts
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
@@ -213,6 +323,8 @@ func (k *K3sAgentResource) Update(ctx context.Context, req resource.UpdateReques
 func (k *K3sAgentResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
 	return []resource.ConfigValidator{
 		&k3sAgentAuthValidator{},
+		&k3sWriteOnlyAuthValidator{defaults: k.defaults},
+		&k3sConfigKeysValidator{server: false, version: k.version},
 	}
 }
 
@@ -243,15 +355,37 @@ func (k *k3sAgentAuthValidator) ValidateResource(ctx context.Context, req resour
 	// Read Terraform state data into the model
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 
-	if err := handlers.NewNodeAuth(ctx, data.Auth).Validate(); err != nil {
+	if err := handlers.NewNodeAuth(ctx, data.Auth).WithWriteOnly(ctx, data.AuthWo).Validate(); err != nil {
 		resp.Diagnostics.AddError("Auth error", err.Error())
 		return
 	}
 
 	// Null is bad, Unknown is fine (because planning server+agent together)
-	if data.Token.IsNull() || data.Server.IsNull() {
+	if (data.Token.IsNull() && data.AgentToken.IsNull() && data.TokenWo.IsNull()) || data.Server.IsNull() {
 		resp.Diagnostics.AddError("empty args", "Token or server cannot be empty strings")
 		return
 	}
 
+	tokens := 0
+	for _, token := range []types.String{data.Token, data.AgentToken, data.TokenWo} {
+		if !token.IsNull() {
+			tokens++
+		}
+	}
+	if tokens > 1 {
+		resp.Diagnostics.AddError("Token error", "more than one of token, agent_token and token_wo were passed, only pass one")
+		return
+	}
+
+	if !data.WaitForReady.IsNull() && !data.WaitForReady.IsUnknown() {
+		if err := handlers.NewWaitForReady(ctx, data.WaitForReady).Validate(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("wait_for_ready"), "Wait for ready", err.Error())
+			return
+		}
+		// Readiness is read from the cluster the kubeconfig points at
+		if !data.KubeConfig.IsUnknown() && strings.TrimSpace(data.KubeConfig.ValueString()) == "" {
+			resp.Diagnostics.AddAttributeError(path.Root("kubeconfig"), "Wait for ready", "wait_for_ready requires a kubeconfig for the cluster")
+			return
+		}
+	}
 }
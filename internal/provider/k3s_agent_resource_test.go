@@ -39,6 +39,10 @@ func TestAccK3sAgentResource(t *testing.T) {
 						"k3s_server.main",
 						tfjsonpath.New("token"),
 					),
+					statecheck.ExpectSensitiveValue(
+						"k3s_server.main",
+						tfjsonpath.New("agent_token"),
+					),
 					statecheck.ExpectKnownValue(
 						"k3s_agent.main[0]",
 						tfjsonpath.New("active"),
@@ -146,5 +150,44 @@ func TestK3sAgentValidateResource(t *testing.T) {
 			}`,
 		}},
 	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:           true,
+			ExpectNonEmptyPlan: true,
+			Config: providerConfig + `
+			resource "k3s_agent" "main" {
+				auth = {
+				host	    = "192.168.1.2"
+				user	    = "ubuntu"
+				private_key = "abc123"
+				}
+				server      = "192.168.1.1"
+				agent_token = "abc123"
+				kubeconfig  = "1asdsad"
+			}`,
+		}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)both token and agent_token were passed, only pass one(.*)`),
+			Config: providerConfig + `
+			resource "k3s_agent" "main" {
+				auth = {
+				host	    = "192.168.1.2"
+				user	    = "ubuntu"
+				private_key = "abc123"
+				}
+				server      = "192.168.1.1"
+				token       = "abc123"
+				agent_token = "abc123"
+				kubeconfig  = "1asdsad"
+			}`,
+		}},
+	})
 
 }
@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+var _ resource.ResourceWithConfigValidators = &K3sCertificateRotationResource{}
+
+var _ resource.ResourceWithConfigure = &K3sCertificateRotationResource{}
+
+type K3sCertificateRotationResource struct {
+	defaults *handlers.DefaultAuth
+}
+
+func NewK3sCertificateRotationResource() resource.Resource {
+	return &K3sCertificateRotationResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (k *K3sCertificateRotationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*K3sProvider)
+	if !ok {
+		resp.Diagnostics.AddError("Provider error", "Could not convert provider data")
+		return
+	}
+	k.defaults = provider.DefaultAuth
+}
+
+// Metadata implements resource.Resource.
+func (k *K3sCertificateRotationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_certificate_rotation"
+}
+
+// Schema implements resource.Resource.
+func (k *K3sCertificateRotationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: ("Rotates the certificates of a k3s server with `k3s certificate rotate` and restarts it. " +
+			"The rotation runs on create, and again whenever `services` or `triggers` change. Destroying only removes it from state."),
+		Attributes: map[string]schema.Attribute{
+			"auth": handlers.NodeAuth{}.Schema(),
+			"services": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Services to rotate the certificates of, e.g. `api-server` or `kubelet`. Rotates all certificates when not passed",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Arbitrary values that rotate the certificates again when changed",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			// Outputs
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the rotation",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"kubeconfig": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Kubeconfig of the server after the rotation",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"cluster_auth": handlers.ClusterAuth{}.Schema(),
+			"certificate_expiry": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Expiry of each certificate in the server's tls directory after the rotation, keyed by certificate file name",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (k *K3sCertificateRotationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data handlers.CertificateRotationClientModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
+	data.Auth = auth.StateObject(ctx)
+	server := k3s.NewK3ServerUninstall(ctx, "")
+
+	if err := data.Create(ctx, &auth, server); err != nil {
+		resp.Diagnostics.AddError("rotating k3s certificates", err.Error())
+		return
+	}
+
+	tflog.Info(ctx, "Created a k3s certificate rotation resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read implements resource.Resource.
+func (k *K3sCertificateRotationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// A rotation is a one off action, there is nothing to read back
+}
+
+// Update implements resource.Resource.
+func (k *K3sCertificateRotationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data handlers.CertificateRotationClientModel
+	var state handlers.CertificateRotationClientModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Everything but the auth requires a new rotation
+	state.Auth = handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults).StateObject(ctx)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete implements resource.Resource.
+func (k *K3sCertificateRotationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "Certificate rotations cannot be undone, removing from state only")
+}
+
+// ConfigValidators implements resource.ResourceWithConfigValidators.
+func (k *K3sCertificateRotationResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		&k3sCertificateRotationValidator{},
+	}
+}
+
+type k3sCertificateRotationValidator struct{}
+
+var _ resource.ConfigValidator = &k3sCertificateRotationValidator{}
+
+// Description implements resource.ConfigValidator.
+func (k *k3sCertificateRotationValidator) Description(context.Context) string {
+	return "Validates the authentication and services to rotate"
+}
+
+// MarkdownDescription implements resource.ConfigValidator.
+func (k *k3sCertificateRotationValidator) MarkdownDescription(context.Context) string {
+	return "Allows either Password or Private Key, and only services known to `k3s certificate rotate`"
+}
+
+// ValidateResource implements resource.ConfigValidator.
+func (k *k3sCertificateRotationValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data handlers.CertificateRotationClientModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if err := handlers.NewNodeAuth(ctx, data.Auth).Validate(); err != nil {
+		resp.Diagnostics.AddError("No auth", err.Error())
+		return
+	}
+
+	if err := data.Validate(ctx); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("services"), "Certificate rotation error", err.Error())
+		return
+	}
+}
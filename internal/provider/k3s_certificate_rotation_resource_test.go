@@ -0,0 +1,47 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestK3sCertificateRotationValidateResource(t *testing.T) {
+	t.Parallel()
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:           true,
+			ExpectNonEmptyPlan: true,
+			Config: providerConfig + `
+			resource "k3s_certificate_rotation" "main" {
+				auth = {
+				host	    = "192.168.1.2"
+				user	    = "ubuntu"
+				private_key = "abc123"
+				}
+				services = ["api-server", "kubelet"]
+			}`,
+		}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)unknown service apiserver(.*)`),
+			Config: providerConfig + `
+			resource "k3s_certificate_rotation" "main" {
+				auth = {
+				host	    = "192.168.1.2"
+				user	    = "ubuntu"
+				private_key = "abc123"
+				}
+				services = ["apiserver"]
+			}`,
+		}},
+	})
+
+}
@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+var _ resource.ResourceWithConfigValidators = &K3sClientCertificateKubeConfigResource{}
+var _ resource.ResourceWithModifyPlan = &K3sClientCertificateKubeConfigResource{}
+
+type K3sClientCertificateKubeConfigResource struct{}
+
+func NewK3sClientCertificateKubeConfigResource() resource.Resource {
+	return &K3sClientCertificateKubeConfigResource{}
+}
+
+// Metadata implements resource.Resource.
+func (k *K3sClientCertificateKubeConfigResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_client_certificate_kubeconfig"
+}
+
+// Schema implements resource.Resource.
+func (k *K3sClientCertificateKubeConfigResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: ("Issues a client certificate for a named user and renders a kubeconfig for it. The key is generated locally and " +
+			"signed by the cluster's client CA through a CertificateSigningRequest approved with `server_kubeconfig`. Kubernetes takes " +
+			"`user` and `groups` from the certificate, so grant them access with your own role bindings. A new certificate is issued " +
+			"on the first apply within `renew_before` of its expiry. Kubernetes can't revoke client certificates, destroying only " +
+			"removes it from the state and it stays valid until it expires."),
+		Attributes: map[string]schema.Attribute{
+			"server_kubeconfig": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Kubeconfig allowed to create and approve certificate signing requests, such as the kubeconfig of a k3s_server resource",
+			},
+			"user": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "User name, the common name of the certificate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"groups": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Groups of the user, the organizations of the certificate",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"ttl": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(handlers.DefaultClientCertificateTtl),
+				MarkdownDescription: "Requested lifetime of the certificate, at least `10m`. The signer may cap it to its own maximum",
+			},
+			"renew_before": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(handlers.DefaultClientCertificateRenewBefore),
+				MarkdownDescription: "How long before its expiry the certificate is issued again, shorter than `ttl`",
+			},
+			// Outputs
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "User of the certificate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"client_certificate": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "PEM encoded client certificate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"client_key": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "PEM encoded private key of the certificate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Expiry of the certificate, in RFC3339",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"kubeconfig": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Kubeconfig of the user, against the cluster of `server_kubeconfig`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (k *K3sClientCertificateKubeConfigResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data handlers.ClientCertificateKubeConfigModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	certificates, err := k3s.NewClientCertificates(ctx, data.ServerKubeConfig.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("creating client certificate kubeconfig", err.Error())
+		return
+	}
+
+	if err := data.Create(ctx, certificates); err != nil {
+		resp.Diagnostics.AddError("creating client certificate kubeconfig", err.Error())
+		return
+	}
+
+	tflog.Info(ctx, "Created a k3s client certificate kubeconfig resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read implements resource.Resource.
+func (k *K3sClientCertificateKubeConfigResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data handlers.ClientCertificateKubeConfigModel
+
+	// Nothing is kept in the cluster once the certificate is issued
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update implements resource.Resource.
+func (k *K3sClientCertificateKubeConfigResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data handlers.ClientCertificateKubeConfigModel
+	var state handlers.ClientCertificateKubeConfigModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	certificates, err := k3s.NewClientCertificates(ctx, data.ServerKubeConfig.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("updating client certificate kubeconfig", err.Error())
+		return
+	}
+
+	if err := state.Update(ctx, data, certificates); err != nil {
+		resp.Diagnostics.AddError("updating client certificate kubeconfig", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete implements resource.Resource.
+func (k *K3sClientCertificateKubeConfigResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Client certificates can't be revoked, they stay valid until expires_at
+	tflog.Warn(ctx, "Removing the client certificate from state, it stays valid until it expires")
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.
+func (k *K3sClientCertificateKubeConfigResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Creating or destroying
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var state handlers.ClientCertificateKubeConfigModel
+	var plan handlers.ClientCertificateKubeConfigModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A new certificate is issued close to its expiry, or for a new ttl
+	state.RenewBefore = plan.RenewBefore
+	if !state.NeedsRenewal(time.Now()) && state.Ttl.Equal(plan.Ttl) {
+		return
+	}
+	for _, attribute := range []string{"client_certificate", "client_key", "expires_at", "kubeconfig"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(attribute), types.StringUnknown())...)
+	}
+}
+
+// ConfigValidators implements resource.ResourceWithConfigValidators.
+func (k *K3sClientCertificateKubeConfigResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		&k3sClientCertificateKubeConfigValidator{},
+	}
+}
+
+type k3sClientCertificateKubeConfigValidator struct{}
+
+var _ resource.ConfigValidator = &k3sClientCertificateKubeConfigValidator{}
+
+// Description implements resource.ConfigValidator.
+func (k *k3sClientCertificateKubeConfigValidator) Description(context.Context) string {
+	return "Validates the ttl and renewal window of the certificate"
+}
+
+// MarkdownDescription implements resource.ConfigValidator.
+func (k *k3sClientCertificateKubeConfigValidator) MarkdownDescription(context.Context) string {
+	return "Allows a ttl of at least 10 minutes and a renew_before shorter than the ttl"
+}
+
+// ValidateResource implements resource.ConfigValidator.
+func (k *k3sClientCertificateKubeConfigValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data handlers.ClientCertificateKubeConfigModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		resp.Diagnostics.AddError("Client certificate error", err.Error())
+		return
+	}
+}
@@ -0,0 +1,43 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestK3sClientCertificateKubeConfigValidateResource(t *testing.T) {
+	t.Parallel()
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:           true,
+			ExpectNonEmptyPlan: true,
+			Config: providerConfig + `
+			resource "k3s_client_certificate_kubeconfig" "main" {
+				server_kubeconfig = "abc123"
+				user              = "jane"
+				groups            = ["developers"]
+				ttl               = "48h"
+				renew_before      = "12h"
+			}`,
+		}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)must be shorter than the ttl(.*)`),
+			Config: providerConfig + `
+			resource "k3s_client_certificate_kubeconfig" "main" {
+				server_kubeconfig = "abc123"
+				user              = "jane"
+				ttl               = "24h"
+				renew_before      = "48h"
+			}`,
+		}},
+	})
+}
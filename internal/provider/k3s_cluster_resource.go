@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+var _ resource.ResourceWithConfigure = &K3sClusterResource{}
+var _ resource.ResourceWithConfigValidators = &K3sClusterResource{}
+
+type K3sClusterResource struct {
+	version  *string
+	defaults *handlers.DefaultAuth
+}
+
+func NewK3sClusterResource() resource.Resource {
+	return &K3sClusterResource{}
+}
+
+// Schema implements resource.Resource.
+func (c *K3sClusterResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: ("Creates a whole k3s cluster from lists of server and agent nodes. " +
+			"The first server initializes the cluster with embedded etcd, the remaining servers join it one at a time, then the agents join in parallel, `parallelism` nodes at a time. " +
+			"Nodes are matched by `name` between applies: new nodes join the cluster, removed nodes are cordoned, drained and deleted from " +
+			"kubernetes before k3s is uninstalled, a drain that doesn't finish within 5m, such as one blocked by a pod disruption budget, halts the update with the node kept, " +
+			"and a node moved to another host is replaced. " +
+			"Config changes roll through the nodes, each restarted node has to be active and Ready, within the `wait_for_ready` timeout or 5m when it isn't set, " +
+			"before the next one restarts, and a node that doesn't come back halts the update with the remaining nodes untouched. " +
+			"Changing the first server replaces the whole cluster. It is up to the consumers of this resource to keep an odd number of servers for etcd quorum."),
+		Attributes: map[string]schema.Attribute{
+			// Inputs
+			"servers": schema.ListNestedAttribute{
+				Required:            true,
+				MarkdownDescription: "Server nodes of the cluster, the first one initializes the cluster",
+				NestedObject:        handlers.ClusterNode{}.NestedObject(),
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplaceIf(
+						initServerChanged,
+						"Changing the first server, which initializes the cluster, requires replacement",
+						"Changing the first server, which initializes the cluster, requires replacement",
+					),
+				},
+			},
+			"agents": schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Agent nodes of the cluster",
+				NestedObject:        handlers.ClusterNode{}.NestedObject(),
+			},
+			"bin_dir": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Value of a path used to put the k3s binary on every node",
+				Default:             stringdefault.StaticString("/usr/local/bin"),
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"registry": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				CustomType:          handlers.YamlType{},
+				MarkdownDescription: "K3s registry of every node",
+				PlanModifiers: []planmodifier.String{
+					handlers.YamlUseStateWhenEqual(),
+				},
+			},
+			"wait_for_ready": handlers.WaitForReady{}.RollingUpdateSchema(),
+			"parallelism": schema.Int64Attribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(k3s.DefaultParallelism),
+				MarkdownDescription: "Most agents installed, read or uninstalled at once",
+			},
+			"update_batch_size": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(1),
+				MarkdownDescription: "Agents restarted at once when their config or the registry changes. Servers always restart one at a time. " +
+					"Every restarted node of a batch has to be active and Ready before the next batch restarts, within the `wait_for_ready` timeout or 5m when it isn't set",
+			},
+			// Outputs
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the k3s cluster resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"kubeconfig": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "KubeConfig for the cluster, pointed at the first server",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"token": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Server token used for joining nodes to the cluster",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"agent_token": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Agent token used for joining agents to the cluster without server level trust",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"server": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Server url of the first server, used for joining nodes to the cluster",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"active": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "The health of the cluster, false if any node isn't active",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"cluster_auth": handlers.ClusterAuth{}.Schema(),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": handlers.TimeoutsSchema(ctx),
+		},
+	}
+}
+
+func initServerChanged(ctx context.Context, req planmodifier.ListRequest, resp *listplanmodifier.RequiresReplaceIfFuncResponse) {
+	var state, plan []handlers.ClusterNode
+	req.StateValue.ElementsAs(ctx, &state, false)
+	req.PlanValue.ElementsAs(ctx, &plan, false)
+	if len(state) == 0 || len(plan) == 0 {
+		return
+	}
+	stateAuth, planAuth := handlers.NewNodeAuth(ctx, state[0].Auth), handlers.NewNodeAuth(ctx, plan[0].Auth)
+	resp.RequiresReplace = !state[0].Name.Equal(plan[0].Name) || !stateAuth.Host.Equal(planAuth.Host)
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (c *K3sClusterResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*K3sProvider)
+	if !ok {
+		resp.Diagnostics.AddError("Provider error", "Could not convert provider data into version")
+		return
+	}
+	if provider.Version != "" {
+		c.version = &provider.Version
+	}
+	c.defaults = provider.DefaultAuth
+}
+
+// Metadata implements resource.Resource.
+func (c *K3sClusterResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cluster"
+}
+
+// Create implements resource.Resource.
+func (c *K3sClusterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data handlers.ClusterModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.SetVersion(c.version)
+
+	createTimeout, diags := data.Timeouts.Create(ctx, handlers.DefaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	if err := data.ResolvePorts(ctx, c.defaults); err != nil {
+		resp.Diagnostics.AddError("creating k3s cluster", err.Error())
+		return
+	}
+	if err := data.Create(ctx, clusterComponents{defaults: c.defaults}); err != nil {
+		resp.Diagnostics.AddError("creating k3s cluster", err.Error())
+		// Keeps the nodes that were created, so they are uninstalled when the cluster is replaced
+		if !data.Id.IsNull() && !data.Id.IsUnknown() {
+			data.Active = types.BoolValue(false)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		}
+		return
+	}
+
+	tflog.Info(ctx, "Created a k3s cluster resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read implements resource.Resource.
+func (c *K3sClusterResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data handlers.ClusterModel
+
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.SetVersion(c.version)
+
+	readTimeout, diags := data.Timeouts.Read(ctx, handlers.DefaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := data.Read(ctx, clusterComponents{defaults: c.defaults}); err != nil {
+		resp.Diagnostics.AddError("reading k3s cluster", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update implements resource.Resource.
+func (c *K3sClusterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data handlers.ClusterModel
+	var state handlers.ClusterModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.SetVersion(c.version)
+
+	updateTimeout, diags := data.Timeouts.Update(ctx, handlers.DefaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	if err := data.ResolvePorts(ctx, c.defaults); err != nil {
+		resp.Diagnostics.AddError("updating k3s cluster", err.Error())
+		return
+	}
+	if err := state.Update(ctx, data, clusterComponents{defaults: c.defaults}); err != nil {
+		// Keep the nodes that were changed before the failure
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		resp.Diagnostics.AddError("updating k3s cluster", err.Error())
+		return
+	}
+
+	state.Timeouts = data.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete implements resource.Resource.
+func (c *K3sClusterResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data handlers.ClusterModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.SetVersion(c.version)
+
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, handlers.DefaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	if err := data.Delete(ctx, clusterComponents{defaults: c.defaults}); err != nil {
+		resp.Diagnostics.AddError("deleting k3s cluster", err.Error())
+		return
+	}
+}
+
+// ConfigValidators implements resource.ResourceWithConfigValidators.
+func (c *K3sClusterResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		&k3sClusterValidator{},
+	}
+}
+
+// Builds the components of each node of a k3s_cluster.
+type clusterComponents struct {
+	defaults *handlers.DefaultAuth
+}
+
+var _ handlers.TClusterComponents = clusterComponents{}
+
+// Auth implements handlers.TClusterComponents.
+func (c clusterComponents) Auth(ctx context.Context, auth types.Object) handlers.TServerSSH {
+	nodeAuth := handlers.NewNodeAuth(ctx, auth).WithDefaults(c.defaults)
+	return &nodeAuth
+}
+
+// Server implements handlers.TClusterComponents.
+func (c clusterComponents) Server(ctx context.Context, model *handlers.ServerClientModel) (handlers.TClusterServer, error) {
+	return model.ToServer(ctx)
+}
+
+// Agent implements handlers.TClusterComponents.
+func (c clusterComponents) Agent(ctx context.Context, model *handlers.AgentClientModel) (handlers.TClusterAgent, error) {
+	return model.ToAgent(ctx)
+}
+
+// NodeRemoval implements handlers.TClusterComponents.
+func (c clusterComponents) NodeRemoval(ctx context.Context, kubeconfig string) (k3s.NodeRemoval, error) {
+	return k3s.NewNodeRemoval(ctx, kubeconfig)
+}
+
+// Validation
+
+type k3sClusterValidator struct{}
+
+var _ resource.ConfigValidator = &k3sClusterValidator{}
+
+// Description implements resource.ConfigValidator.
+func (k *k3sClusterValidator) Description(context.Context) string {
+	return "Validates the nodes of the cluster"
+}
+
+// MarkdownDescription implements resource.ConfigValidator.
+func (k *k3sClusterValidator) MarkdownDescription(context.Context) string {
+	return "Requires at least one server, unique node names and hosts, and either a password or private key per node"
+}
+
+// ValidateResource implements resource.ConfigValidator.
+func (k *k3sClusterValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data handlers.ClusterModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.Validate(ctx); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("servers"), "Cluster nodes", err.Error())
+		return
+	}
+
+	if !data.WaitForReady.IsNull() && !data.WaitForReady.IsUnknown() {
+		if err := handlers.NewWaitForReady(ctx, data.WaitForReady).Validate(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("wait_for_ready"), "Wait for ready", err.Error())
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestK3sClusterValidateResource(t *testing.T) {
+	t.Parallel()
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:           true,
+			ExpectNonEmptyPlan: true,
+			Config: providerConfig + `
+			resource "k3s_cluster" "main" {
+				servers = [
+					{ name = "server-0", auth = { host = "10.0.0.1", password = "abc123" } },
+					{ name = "server-1", auth = { host = "10.0.0.2", password = "abc123" } },
+				]
+				agents = [
+					{ name = "agent-0", auth = { host = "10.0.1.1", password = "abc123" } },
+				]
+			}`,
+		}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)used more than once(.*)`),
+			Config: providerConfig + `
+			resource "k3s_cluster" "main" {
+				servers = [
+					{ name = "node", auth = { host = "10.0.0.1", password = "abc123" } },
+				]
+				agents = [
+					{ name = "node", auth = { host = "10.0.1.1", password = "abc123" } },
+				]
+			}`,
+		}},
+	})
+}
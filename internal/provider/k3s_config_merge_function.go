@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+var _ function.Function = &ConfigMergeFunction{}
+
+type ConfigMergeFunction struct{}
+
+func NewConfigMergeFunction() function.Function {
+	return &ConfigMergeFunction{}
+}
+
+// Metadata implements function.Function.
+func (k *ConfigMergeFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "config_merge"
+}
+
+// Definition implements function.Function.
+func (k *ConfigMergeFunction) Definition(_ context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Deep merges k3s config yaml",
+		MarkdownDescription: ("Merges yaml documents such as k3s configs or registries the same way k3s_server merges its " +
+			"configs: nested maps are merged, any other value of a later document replaces the earlier one."),
+		VariadicParameter: function.StringParameter{
+			Name:                "configs",
+			MarkdownDescription: "Yaml documents to merge, later ones taking precedence",
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run implements function.Function.
+func (k *ConfigMergeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var configs []string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &configs))
+	if resp.Error != nil {
+		return
+	}
+
+	merged, err := k3s.MergeYamlStrings(configs...)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("merging configs: %s", err.Error()))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, merged))
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+// Validates the keys of the raw `config` of a server or agent against the
+// keys known to k3s, for the provider's k3s version when pinned.
+type k3sConfigKeysValidator struct {
+	server  bool
+	version *string
+}
+
+var _ resource.ConfigValidator = &k3sConfigKeysValidator{}
+
+// Description implements resource.ConfigValidator.
+func (k *k3sConfigKeysValidator) Description(context.Context) string {
+	return "Validates the k3s config keys"
+}
+
+// MarkdownDescription implements resource.ConfigValidator.
+func (k *k3sConfigKeysValidator) MarkdownDescription(context.Context) string {
+	return "Warns of config keys unknown to k3s, and rejects values of the wrong type"
+}
+
+// ValidateResource implements resource.ConfigValidator.
+func (k *k3sConfigKeysValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config handlers.YamlValue
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("config"), &config)...)
+	if resp.Diagnostics.HasError() || config.IsNull() || config.IsUnknown() {
+		return
+	}
+
+	// Invalid yaml is reported by the attribute itself
+	parsed, err := k3s.ParseYamlString(config.StringValue)
+	if err != nil {
+		return
+	}
+
+	version := ""
+	if k.version != nil {
+		version = *k.version
+	}
+
+	errs, err := k3s.ValidateConfigKeys(parsed, k.server, version)
+	if err != nil {
+		resp.Diagnostics.AddError("Config", err.Error())
+		return
+	}
+	for _, err := range errs {
+		var unknown *k3s.UnknownConfigKeyError
+		if errors.As(err, &unknown) {
+			resp.Diagnostics.AddAttributeWarning(path.Root("config"), "Unknown k3s config key", err.Error())
+			continue
+		}
+		resp.Diagnostics.AddAttributeError(path.Root("config"), "Invalid k3s config", err.Error())
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+var _ datasource.DataSourceWithConfigure = &K3sEtcdSnapshotsData{}
+
+type K3sEtcdSnapshotsData struct {
+	defaults *handlers.DefaultAuth
+}
+
+func NewK3sEtcdSnapshotsData() datasource.DataSource {
+	return &K3sEtcdSnapshotsData{}
+}
+
+// Configure implements datasource.DataSourceWithConfigure.
+func (k *K3sEtcdSnapshotsData) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*K3sProvider)
+	if !ok {
+		resp.Diagnostics.AddError("Provider error", "Could not convert provider data")
+		return
+	}
+	k.defaults = provider.DefaultAuth
+}
+
+// Metadata implements datasource.DataSource.
+func (k *K3sEtcdSnapshotsData) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_etcd_snapshots"
+}
+
+// Read implements datasource.DataSource.
+func (k *K3sEtcdSnapshotsData) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data handlers.K3sEtcdSnapshots
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
+	if err := auth.Validate(); err != nil {
+		resp.Diagnostics.AddError("No auth", err.Error())
+		return
+	}
+
+	server := k3s.NewK3ServerUninstall(ctx, "")
+	if err := data.Read(ctx, &auth, server); err != nil {
+		resp.Diagnostics.AddError("error reading etcd snapshots", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Schema implements datasource.DataSource.
+func (k *K3sEtcdSnapshotsData) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: ("Lists the etcd snapshots available to a k3s server running with embedded etcd. " +
+			"Useful for picking restore points or alerting on missing backups."),
+		Attributes: map[string]schema.Attribute{
+			"auth":      handlers.NodeAuth{}.Schema(),
+			"snapshots": handlers.EtcdSnapshot{}.Schema(),
+		},
+	}
+}
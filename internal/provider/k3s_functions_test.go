@@ -0,0 +1,99 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"k8s.io/client-go/tools/clientcmd"
+	api "k8s.io/client-go/tools/clientcmd/api"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/provider"
+)
+
+func testKubeConfig(t *testing.T) string {
+	config := api.NewConfig()
+	config.Clusters["default"] = &api.Cluster{Server: "https://127.0.0.1:6443", CertificateAuthorityData: []byte("ca")}
+	config.AuthInfos["default"] = &api.AuthInfo{ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key")}
+	config.Contexts["default"] = &api.Context{Cluster: "default", AuthInfo: "default"}
+	config.CurrentContext = "default"
+	rendered, err := clientcmd.Write(*config)
+	if err != nil {
+		t.Fatalf("Writing kubeconfig: %v", err.Error())
+	}
+	return string(rendered)
+}
+
+func runFunction(ctx context.Context, f function.Function, result attr.Value, args ...attr.Value) function.RunResponse {
+	resp := function.RunResponse{Result: function.NewResultData(result)}
+	f.Run(ctx, function.RunRequest{Arguments: function.NewArgumentsData(args)}, &resp)
+	return resp
+}
+
+func TestKubeConfigSetServerFunction(t *testing.T) {
+	t.Parallel()
+
+	resp := runFunction(t.Context(), provider.NewKubeConfigSetServerFunction(), types.StringUnknown(),
+		types.StringValue(testKubeConfig(t)), types.StringValue("https://lb.example.com:443"))
+	if resp.Error != nil {
+		t.Fatalf("Expected nil err but found: %v", resp.Error)
+	}
+	config, err := clientcmd.Load([]byte(resp.Result.Value().(types.String).ValueString()))
+	if err != nil {
+		t.Fatalf("Expected a valid kubeconfig, got %v", err.Error())
+	}
+	if config.Clusters["default"].Server != "https://lb.example.com:443" {
+		t.Errorf("Expected the new server, got %s", config.Clusters["default"].Server)
+	}
+
+	resp = runFunction(t.Context(), provider.NewKubeConfigSetServerFunction(), types.StringUnknown(),
+		types.StringValue(testKubeConfig(t)), types.StringValue("lb.example.com"))
+	if resp.Error == nil || *resp.Error.FunctionArgument != 1 {
+		t.Errorf("Expected an error on the server argument, got %v", resp.Error)
+	}
+}
+
+func TestKubeConfigExtractAuthFunction(t *testing.T) {
+	t.Parallel()
+
+	resp := runFunction(t.Context(), provider.NewKubeConfigExtractAuthFunction(),
+		types.ObjectUnknown(handlers.ClusterAuth{}.AttributeTypes()), types.StringValue(testKubeConfig(t)))
+	if resp.Error != nil {
+		t.Fatalf("Expected nil err but found: %v", resp.Error)
+	}
+	auth := resp.Result.Value().(basetypes.ObjectValue).Attributes()
+	if !auth["client_key_data"].Equal(types.StringValue("key")) || !auth["server"].Equal(types.StringValue("https://127.0.0.1:6443")) {
+		t.Errorf("Unexpected auth %v", auth)
+	}
+
+	resp = runFunction(t.Context(), provider.NewKubeConfigExtractAuthFunction(),
+		types.ObjectUnknown(handlers.ClusterAuth{}.AttributeTypes()), types.StringValue("{"))
+	if resp.Error == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestConfigMergeFunction(t *testing.T) {
+	t.Parallel()
+
+	configs := types.TupleValueMust([]attr.Type{types.StringType, types.StringType}, []attr.Value{
+		types.StringValue("node-label: [a=b]\netcd-s3:\n  bucket: one\n  region: us-east-1\n"),
+		types.StringValue("etcd-s3:\n  bucket: two\n"),
+	})
+	resp := runFunction(t.Context(), provider.NewConfigMergeFunction(), types.StringUnknown(), configs)
+	if resp.Error != nil {
+		t.Fatalf("Expected nil err but found: %v", resp.Error)
+	}
+	expected := "etcd-s3:\n  bucket: two\n  region: us-east-1\nnode-label:\n- a=b\n"
+	if got := resp.Result.Value().(types.String).ValueString(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	bad := types.TupleValueMust([]attr.Type{types.StringType}, []attr.Value{types.StringValue("[")})
+	if resp := runFunction(t.Context(), provider.NewConfigMergeFunction(), types.StringUnknown(), bad); resp.Error == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
@@ -10,12 +10,30 @@ import (
 	"striveworks.us/terraform-provider-k3s/internal/k3s"
 )
 
-type K3sKubeConfigData struct{}
+var _ datasource.DataSourceWithConfigure = &K3sKubeConfigData{}
+
+type K3sKubeConfigData struct {
+	defaults *handlers.DefaultAuth
+}
 
 func NewK3sKubeConfigData() datasource.DataSource {
 	return &K3sKubeConfigData{}
 }
 
+// Configure implements datasource.DataSourceWithConfigure.
+func (k *K3sKubeConfigData) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*K3sProvider)
+	if !ok {
+		resp.Diagnostics.AddError("Provider error", "Could not convert provider data")
+		return
+	}
+	k.defaults = provider.DefaultAuth
+}
+
 // Metadata implements datasource.DataSource.
 func (k *K3sKubeConfigData) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_kubeconfig"
@@ -29,7 +47,7 @@ func (k *K3sKubeConfigData) Read(ctx context.Context, req datasource.ReadRequest
 		return
 	}
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
 	server := k3s.NewK3ServerUninstall(ctx, "")
 	if err := data.Read(ctx, &auth, server); err != nil {
 		resp.Diagnostics.AddError("error reading kubeconfig", err.Error())
@@ -43,7 +61,9 @@ func (k *K3sKubeConfigData) Read(ctx context.Context, req datasource.ReadRequest
 func (k *K3sKubeConfigData) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: ("A utility for reading and manipulating kubeconfig. Common use case would be to nicely extract " +
-			"the auth credentials or overridding the server url for a load balancer url or dns name."),
+			"the auth credentials or overridding the server url for a load balancer url or dns name. " +
+			"Data sources can't take write-only arguments, so the ssh credentials and outputs are stored in state, " +
+			"use the ephemeral `k3s_kubeconfig` to keep them out of it."),
 		Attributes: map[string]schema.Attribute{
 			"auth":         handlers.NodeAuth{}.Schema(),
 			"cluster_auth": handlers.ClusterAuth{}.Schema(),
@@ -60,6 +80,30 @@ func (k *K3sKubeConfigData) Schema(_ context.Context, req datasource.SchemaReque
 				Optional:            true,
 				MarkdownDescription: "Override the api server's hostname",
 			},
+			"port": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Override the api server's port, such as the one of a load balancer in front of the servers",
+			},
+			"tls_server_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name to verify the api server's certificate against, when `hostname` isn't one of its SANs",
+			},
+			"certificate_authority": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded CA to verify the api server with instead of the cluster's, for endpoints serving another certificate",
+			},
+			"context_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the kubeconfig context, `default` unless set",
+			},
+			"cluster_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the kubeconfig cluster, `default` unless set",
+			},
+			"user_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the kubeconfig user, `default` unless set",
+			},
 		},
 	}
 }
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+var _ ephemeral.EphemeralResourceWithConfigure = &K3sKubeConfigEphemeral{}
+
+type K3sKubeConfigEphemeral struct {
+	defaults *handlers.DefaultAuth
+}
+
+func NewK3sKubeConfigEphemeral() ephemeral.EphemeralResource {
+	return &K3sKubeConfigEphemeral{}
+}
+
+// Configure implements ephemeral.EphemeralResourceWithConfigure.
+func (k *K3sKubeConfigEphemeral) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*K3sProvider)
+	if !ok {
+		resp.Diagnostics.AddError("Provider error", "Could not convert provider data")
+		return
+	}
+	k.defaults = provider.DefaultAuth
+}
+
+// Metadata implements ephemeral.EphemeralResource.
+func (k *K3sKubeConfigEphemeral) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kubeconfig"
+}
+
+// Open implements ephemeral.EphemeralResource.
+func (k *K3sKubeConfigEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data handlers.K3sKubeConfig
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
+	server := k3s.NewK3ServerUninstall(ctx, "")
+	if err := data.Read(ctx, &auth, server); err != nil {
+		resp.Diagnostics.AddError("error reading kubeconfig", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// Schema implements ephemeral.EphemeralResource.
+func (k *K3sKubeConfigEphemeral) Schema(_ context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: ("Reads the admin kubeconfig of a k3s server without persisting it to state or plan. " +
+			"Use it to configure providers such as kubernetes or helm, the credentials are only kept for the duration of the run."),
+		Attributes: map[string]schema.Attribute{
+			"auth":         handlers.NodeAuth{}.Schema(),
+			"cluster_auth": handlers.ClusterAuth{}.Schema(),
+			"allow_empty": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "If this is true, it will allow a missing kubeconfig and set null to all outputs",
+			},
+			"kubeconfig": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Output of the kubeconfig from a k3s_server resource",
+			},
+			"hostname": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Override the api server's hostname",
+			},
+			"port": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Override the api server's port, such as the one of a load balancer in front of the servers",
+			},
+			"tls_server_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name to verify the api server's certificate against, when `hostname` isn't one of its SANs",
+			},
+			"certificate_authority": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded CA to verify the api server with instead of the cluster's, for endpoints serving another certificate",
+			},
+			"context_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the kubeconfig context, `default` unless set",
+			},
+			"cluster_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the kubeconfig cluster, `default` unless set",
+			},
+			"user_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the kubeconfig user, `default` unless set",
+			},
+		},
+	}
+}
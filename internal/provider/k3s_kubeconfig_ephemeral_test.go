@@ -0,0 +1,35 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestK3sKubeconfigValidateEphemeral(t *testing.T) {
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)(.*)`),
+			Config: providerConfig + `
+			ephemeral "k3s_kubeconfig" "main" {
+				auth = {
+				host	    = "192.168.1.2"
+				user	    = "ubuntu"
+				private_key = "abc123"
+				password    = "abc123"
+				}
+ 			}`,
+		}},
+	})
+
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+var _ function.Function = &KubeConfigExtractAuthFunction{}
+
+type KubeConfigExtractAuthFunction struct{}
+
+func NewKubeConfigExtractAuthFunction() function.Function {
+	return &KubeConfigExtractAuthFunction{}
+}
+
+// Metadata implements function.Function.
+func (k *KubeConfigExtractAuthFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "kubeconfig_extract_auth"
+}
+
+// Definition implements function.Function.
+func (k *KubeConfigExtractAuthFunction) Definition(_ context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Extracts the credentials of a kubeconfig",
+		MarkdownDescription: ("Returns the server, CA and client certificate and key of the kubeconfig's current context, " +
+			"already base64 decoded, the same as the `cluster_auth` attribute of k3s_server. " +
+			"Useful to configure providers such as kubernetes or helm."),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "kubeconfig",
+				MarkdownDescription: "Kubeconfig to read, such as the kubeconfig of a k3s_server resource",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: handlers.ClusterAuth{}.AttributeTypes(),
+		},
+	}
+}
+
+// Run implements function.Function.
+func (k *KubeConfigExtractAuthFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var kubeconfig string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &kubeconfig))
+	if resp.Error != nil {
+		return
+	}
+
+	clusterAuth, err := handlers.BuildClusterAuth(kubeconfig)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "parsing kubeconfig: "+err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, clusterAuth.ToObject(ctx)))
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+var _ function.Function = &KubeConfigSetServerFunction{}
+
+type KubeConfigSetServerFunction struct{}
+
+func NewKubeConfigSetServerFunction() function.Function {
+	return &KubeConfigSetServerFunction{}
+}
+
+// Metadata implements function.Function.
+func (k *KubeConfigSetServerFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "kubeconfig_set_server"
+}
+
+// Definition implements function.Function.
+func (k *KubeConfigSetServerFunction) Definition(_ context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Points a kubeconfig at another api server",
+		MarkdownDescription: ("Replaces the server url of the cluster in the kubeconfig's current context, " +
+			"such as with a load balancer in front of the servers. Credentials and names are kept."),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "kubeconfig",
+				MarkdownDescription: "Kubeconfig to update, such as the kubeconfig of a k3s_server resource",
+			},
+			function.StringParameter{
+				Name:                "server",
+				MarkdownDescription: "Url of the api server, such as `https://k3s.example.com:6443`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run implements function.Function.
+func (k *KubeConfigSetServerFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var kubeconfig, server string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &kubeconfig, &server))
+	if resp.Error != nil {
+		return
+	}
+
+	clusterAuth, err := handlers.BuildClusterAuth(kubeconfig)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "parsing kubeconfig: "+err.Error())
+		return
+	}
+	if err := clusterAuth.UpdateServer(server); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, clusterAuth.KubeConfig()))
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+var _ datasource.DataSource = &K3sMergedKubeConfigData{}
+
+type K3sMergedKubeConfigData struct{}
+
+func NewK3sMergedKubeConfigData() datasource.DataSource {
+	return &K3sMergedKubeConfigData{}
+}
+
+// Metadata implements datasource.DataSource.
+func (k *K3sMergedKubeConfigData) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_merged_kubeconfig"
+}
+
+// Read implements datasource.DataSource.
+func (k *K3sMergedKubeConfigData) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data handlers.MergedKubeConfig
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.Read(ctx); err != nil {
+		resp.Diagnostics.AddError("error merging kubeconfigs", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Schema implements datasource.DataSource.
+func (k *K3sMergedKubeConfigData) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: ("Merges the kubeconfigs of several clusters into one, for tools such as CI that switch between them " +
+			"with `kubectl --context`. The current context of each kubeconfig is kept, with its context, cluster and user renamed " +
+			"after the entry so they don't collide. Entries repeating a name are dropped when identical and rejected otherwise."),
+		Attributes: map[string]schema.Attribute{
+			"kubeconfigs": handlers.NamedKubeConfig{}.Schema(),
+			"current_context": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the entry to use as the current context, the first one unless set",
+			},
+			"kubeconfig": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Merged kubeconfig",
+			},
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestK3sMergedKubeconfigValidateDatasource(t *testing.T) {
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)parsing kubeconfig prod(.*)`),
+			Config: providerConfig + `
+			data "k3s_merged_kubeconfig" "main" {
+				kubeconfigs = [{
+					name       = "prod"
+					kubeconfig = "{"
+				}]
+ 			}`,
+		}},
+	})
+}
@@ -3,12 +3,15 @@ package provider
 import (
 	"context"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"striveworks.us/terraform-provider-k3s/internal/handlers"
 )
@@ -17,9 +20,11 @@ import (
 
 var _ resource.ResourceWithConfigValidators = &K3sServerResource{}
 var _ resource.ResourceWithConfigure = &K3sServerResource{}
+var _ resource.ResourceWithImportState = &K3sServerResource{}
 
 type K3sServerResource struct {
-	version *string
+	version  *string
+	defaults *handlers.DefaultAuth
 }
 
 func NewK3sServerResource() resource.Resource {
@@ -27,14 +32,17 @@ func NewK3sServerResource() resource.Resource {
 }
 
 // Schema implements resource.ResourceWithImportState.
-func (s *K3sServerResource) Schema(context context.Context, resource resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (s *K3sServerResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 1,
 		MarkdownDescription: ("Creates a k3s server resource. Only one of `password` or `private_key` can be passed.\n" +
 			"If ran in highly available mode, it is up to the consumers of this module to correctly implement " +
 			"the raft protocol and create an odd number of ha nodes. Due to how HA works, we do not offer a method to " +
 			"gracefully delete a controller node from the cluster before running `k3s-uninstall.sh` during deletion of this resource."),
 		Attributes: map[string]schema.Attribute{
-			"auth": handlers.NodeAuth{}.Schema(),
+			"auth":            handlers.NodeAuth{}.Schema(),
+			"auth_wo":         handlers.AuthWriteOnly{}.Schema(),
+			"auth_wo_version": handlers.WriteOnlyVersionSchema("auth_wo"),
 			// Inputs
 			"bin_dir": schema.StringAttribute{
 				Optional:            true,
@@ -45,11 +53,22 @@ func (s *K3sServerResource) Schema(context context.Context, resource resource.Sc
 			// Config
 			"config": schema.StringAttribute{
 				Optional:            true,
+				Computed:            true,
+				CustomType:          handlers.YamlType{},
 				MarkdownDescription: "K3s server config",
+				PlanModifiers: []planmodifier.String{
+					handlers.YamlUseStateWhenEqual(),
+				},
 			},
+			"server_config": handlers.ServerConfig{}.Schema(),
 			"registry": schema.StringAttribute{
 				Optional:            true,
+				Computed:            true,
+				CustomType:          handlers.YamlType{},
 				MarkdownDescription: "K3s server registry",
+				PlanModifiers: []planmodifier.String{
+					handlers.YamlUseStateWhenEqual(),
+				},
 			},
 			// Outputs
 			"id": schema.StringAttribute{
@@ -75,6 +94,14 @@ func (s *K3sServerResource) Schema(context context.Context, resource resource.Sc
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"agent_token": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Agent token used for joining agents to the cluster without server level trust",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"server": schema.StringAttribute{
 				Computed: true,
 				// Optional:            false,
@@ -83,6 +110,14 @@ func (s *K3sServerResource) Schema(context context.Context, resource resource.Sc
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"certificate_expiry": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Expiry of each certificate in the server's tls directory, keyed by certificate file name. k3s only renews certificates on restart within 90 days of expiry, see `k3s_certificate_rotation` to rotate them sooner",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"active": schema.BoolAttribute{
 				Computed:            true,
 				MarkdownDescription: "The health of the server",
@@ -92,7 +127,12 @@ func (s *K3sServerResource) Schema(context context.Context, resource resource.Sc
 			},
 			"highly_available": handlers.HaConfig{}.Schema(),
 			"oidc":             handlers.OidcConfig{}.Schema(),
+			"datastore":        handlers.DatastoreConfig{}.Schema(),
 			"cluster_auth":     handlers.ClusterAuth{}.Schema(),
+			"wait_for_ready":   handlers.WaitForReady{}.CreateOnlySchema(),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": handlers.TimeoutsSchema(ctx),
 		},
 	}
 }
@@ -111,19 +151,32 @@ func (s *K3sServerResource) Configure(ctx context.Context, req resource.Configur
 	if provider.Version != "" {
 		s.version = &provider.Version
 	}
+	s.defaults = provider.DefaultAuth
 }
 
 // Create implements resource.ResourceWithImportState.
 func (s *K3sServerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data handlers.ServerClientModel
+	var config handlers.ServerClientModel
 
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	data.SetWriteOnly(ctx, config)
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	createTimeout, diags := data.Timeouts.Create(ctx, handlers.DefaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithWriteOnly(ctx, data.AuthWo).WithDefaults(s.defaults)
+	data.Auth = auth.StateObject(ctx)
 	server, err := data.ToServer(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("creating k3s server", err.Error())
@@ -134,6 +187,9 @@ func (s *K3sServerResource) Create(ctx context.Context, req resource.CreateReque
 		resp.Diagnostics.AddError("creating k3s server", err.Error())
 		return
 	}
+	for _, warning := range data.Warnings() {
+		resp.Diagnostics.AddWarning("k3s server", warning)
+	}
 
 	tflog.Info(ctx, "Created a k3s server resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -149,7 +205,15 @@ func (s *K3sServerResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, handlers.DefaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(s.defaults)
 	server, err := data.ToServer(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("creating k3s server", err.Error())
@@ -177,7 +241,15 @@ func (s *K3sServerResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	readTimeout, diags := data.Timeouts.Read(ctx, handlers.DefaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(s.defaults)
 	server, err := data.ToServer(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("creating k3s server", err.Error())
@@ -188,10 +260,43 @@ func (s *K3sServerResource) Read(ctx context.Context, req resource.ReadRequest,
 		resp.Diagnostics.AddError("reading k3s server", err.Error())
 		return
 	}
+	for _, warning := range data.Warnings() {
+		resp.Diagnostics.AddWarning("k3s server", warning)
+	}
 
 	resp.Diagnostics.Append(req.State.Set(ctx, &data)...)
 }
 
+// ImportState implements resource.ResourceWithImportState.
+func (s *K3sServerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	ctx, cancel := context.WithTimeout(ctx, handlers.DefaultReadTimeout)
+	defer cancel()
+
+	auth, err := handlers.NewImportNodeAuth(req.ID, "server", s.defaults)
+	if err != nil {
+		resp.Diagnostics.AddError("importing k3s server", err.Error())
+		return
+	}
+
+	data := handlers.ServerClientModel{Auth: auth.ToObject(ctx)}
+	server, err := data.ToServer(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("importing k3s server", err.Error())
+		return
+	}
+
+	if err := data.Import(ctx, &auth, server); err != nil {
+		resp.Diagnostics.AddError("importing k3s server", err.Error())
+		return
+	}
+	for _, warning := range data.Warnings() {
+		resp.Diagnostics.AddWarning("k3s server", warning)
+	}
+
+	tflog.Info(ctx, "Imported a k3s server resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
 // Update implements resource.ResourceWithImportState.
 func (s *K3sServerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data handlers.ServerClientModel
@@ -205,7 +310,16 @@ func (s *K3sServerResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
-	auth := handlers.NewNodeAuth(ctx, data.Auth)
+	updateTimeout, diags := data.Timeouts.Update(ctx, handlers.DefaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithWriteOnly(ctx, data.AuthWo).WithDefaults(s.defaults)
+	data.Auth = auth.StateObject(ctx)
 	server, err := data.ToServer(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("creating k3s server", err.Error())
@@ -218,6 +332,13 @@ func (s *K3sServerResource) Update(ctx context.Context, req resource.UpdateReque
 	}
 
 	// Save data into Terraform state
+	// Only applies on create, kept in line with the plan
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("wait_for_ready"), &state.WaitForReady)...)
+	// Holds the write-only token's version, a new version replaces the server instead
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("highly_available"), &state.HaConfig)...)
+	state.Auth = data.Auth
+	state.AuthWoVersion = data.AuthWoVersion
+	state.Timeouts = data.Timeouts
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
@@ -225,6 +346,8 @@ func (s *K3sServerResource) Update(ctx context.Context, req resource.UpdateReque
 func (s *K3sServerResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
 	return []resource.ConfigValidator{
 		&k3sServerAuthValdiator{},
+		&k3sWriteOnlyAuthValidator{defaults: s.defaults},
+		&k3sConfigKeysValidator{server: true, version: s.version},
 	}
 }
 
@@ -249,7 +372,7 @@ func (k *k3sServerAuthValdiator) ValidateResource(ctx context.Context, req resou
 	// Read Terraform state data into the model
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 
-	if err := handlers.NewNodeAuth(ctx, data.Auth).Validate(); err != nil {
+	if err := handlers.NewNodeAuth(ctx, data.Auth).WithWriteOnly(ctx, data.AuthWo).Validate(); err != nil {
 		resp.Diagnostics.AddError("No auth", err.Error())
 		return
 	}
@@ -260,4 +383,41 @@ func (k *k3sServerAuthValdiator) ValidateResource(ctx context.Context, req resou
 			return
 		}
 	}
+
+	if !data.OidcConfig.IsNull() && !data.OidcConfig.IsUnknown() {
+		if err := handlers.NewOidcConfig(ctx, data.OidcConfig).Validate(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("oidc"), "OIDC", err.Error())
+			return
+		}
+	}
+
+	if !data.ServerConfig.IsNull() && !data.ServerConfig.IsUnknown() {
+		if err := handlers.NewServerConfig(ctx, data.ServerConfig).Validate(ctx); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("server_config"), "Server config", err.Error())
+			return
+		}
+	}
+
+	if !data.WaitForReady.IsNull() && !data.WaitForReady.IsUnknown() {
+		if err := handlers.NewWaitForReady(ctx, data.WaitForReady).Validate(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("wait_for_ready"), "Wait for ready", err.Error())
+			return
+		}
+	}
+
+	if !data.DatastoreConfig.IsNull() && !data.DatastoreConfig.IsUnknown() {
+		if err := handlers.NewDatastoreConfig(ctx, data.DatastoreConfig).Validate(); err != nil {
+			resp.Diagnostics.AddError("Datastore", err.Error())
+			return
+		}
+
+		if !data.HaConfig.IsNull() && !data.HaConfig.IsUnknown() && handlers.NewHaConfig(ctx, data.HaConfig).ClusterInit.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("datastore"),
+				"Datastore",
+				"an external datastore cannot be used with cluster_init, which starts embedded etcd",
+			)
+			return
+		}
+	}
 }
@@ -291,6 +291,51 @@ func TestK3sServerValidateResource(t *testing.T) {
 					token = "absdad"
 				}
 			}`,
+		}, {
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)cannot be used with cluster_init(.*)`),
+			Config: providerConfig + `
+			resource "k3s_server" "main" {
+				auth = {
+					host        = "192.168.1.1"
+					user        = "ubuntu"
+					private_key = "somelongkey"
+				}
+				highly_available = {
+					cluster_init = true
+				}
+				datastore = {
+					endpoint = "postgres://db:5432/k3s"
+				}
+			}`,
+		}, {
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)unknown component traefik-ingress to disable(.*)`),
+			Config: providerConfig + `
+			resource "k3s_server" "main" {
+				auth = {
+					host        = "192.168.1.1"
+					user        = "ubuntu"
+					private_key = "somelongkey"
+				}
+				server_config = {
+					disable = ["traefik-ingress"]
+				}
+			}`,
+		}, {
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)"secrets-encryption" expects a bool(.*)`),
+			Config: providerConfig + `
+			resource "k3s_server" "main" {
+				auth = {
+					host        = "192.168.1.1"
+					user        = "ubuntu"
+					private_key = "somelongkey"
+				}
+				config = yamlencode({
+					secrets-encryption = "yes please"
+				})
+			}`,
 		}},
 	})
 }
@@ -0,0 +1,293 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+var _ resource.ResourceWithConfigValidators = &K3sServiceAccountKubeConfigResource{}
+var _ resource.ResourceWithModifyPlan = &K3sServiceAccountKubeConfigResource{}
+
+type K3sServiceAccountKubeConfigResource struct{}
+
+func NewK3sServiceAccountKubeConfigResource() resource.Resource {
+	return &K3sServiceAccountKubeConfigResource{}
+}
+
+// Metadata implements resource.Resource.
+func (k *K3sServiceAccountKubeConfigResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_account_kubeconfig"
+}
+
+// Schema implements resource.Resource.
+func (k *K3sServiceAccountKubeConfigResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: ("Creates a service account bound to a single role and renders a kubeconfig for it, so downstream tools " +
+			"don't need the cluster admin kubeconfig. The token is requested through the TokenRequest api and expires after `ttl`, " +
+			"a new one is requested on the first apply after it expired. Destroying deletes the role binding and the service account, " +
+			"which revokes every token issued for it."),
+		Attributes: map[string]schema.Attribute{
+			"server_kubeconfig": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Kubeconfig allowed to manage service accounts and role bindings, such as the kubeconfig of a k3s_server resource",
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the service account and its role binding",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"namespace": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("default"),
+				MarkdownDescription: "Namespace of the service account, and of its role binding unless `cluster_wide`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cluster_role": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "ClusterRole to bind the service account to. Only one of `cluster_role` or `role` can be passed",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"role": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Role in the namespace to bind the service account to",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cluster_wide": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				MarkdownDescription: "Bind the `cluster_role` with a ClusterRoleBinding instead of a RoleBinding in the namespace",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"ttl": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("1h"),
+				MarkdownDescription: "Lifetime of the token, at least `10m`. The api server may cap it to its own maximum",
+			},
+			// Outputs
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Namespace and name of the service account",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"token": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Token bound to the service account",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Expiry of the token, in RFC3339",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"kubeconfig": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Kubeconfig of the service account, against the cluster of `server_kubeconfig`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (k *K3sServiceAccountKubeConfigResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data handlers.ServiceAccountKubeConfigModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts, err := k3s.NewServiceAccounts(ctx, data.ServerKubeConfig.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("creating service account kubeconfig", err.Error())
+		return
+	}
+
+	if err := data.Create(ctx, accounts); err != nil {
+		resp.Diagnostics.AddError("creating service account kubeconfig", err.Error())
+		return
+	}
+
+	tflog.Info(ctx, "Created a k3s service account kubeconfig resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read implements resource.Resource.
+func (k *K3sServiceAccountKubeConfigResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data handlers.ServiceAccountKubeConfigModel
+
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts, err := k3s.NewServiceAccounts(ctx, data.ServerKubeConfig.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("reading service account kubeconfig", err.Error())
+		return
+	}
+
+	exists, err := data.Read(ctx, accounts)
+	if err != nil {
+		resp.Diagnostics.AddError("reading service account kubeconfig", err.Error())
+		return
+	}
+
+	if !exists {
+		tflog.Warn(ctx, "Service account no longer exists, removing from state")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update implements resource.Resource.
+func (k *K3sServiceAccountKubeConfigResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data handlers.ServiceAccountKubeConfigModel
+	var state handlers.ServiceAccountKubeConfigModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts, err := k3s.NewServiceAccounts(ctx, data.ServerKubeConfig.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("updating service account kubeconfig", err.Error())
+		return
+	}
+
+	if err := state.Update(ctx, data, accounts); err != nil {
+		resp.Diagnostics.AddError("updating service account kubeconfig", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete implements resource.Resource.
+func (k *K3sServiceAccountKubeConfigResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data handlers.ServiceAccountKubeConfigModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts, err := k3s.NewServiceAccounts(ctx, data.ServerKubeConfig.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("deleting service account kubeconfig", err.Error())
+		return
+	}
+
+	if err := data.Delete(ctx, accounts); err != nil {
+		resp.Diagnostics.AddError("deleting service account kubeconfig", err.Error())
+		return
+	}
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.
+func (k *K3sServiceAccountKubeConfigResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Creating or destroying
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var state handlers.ServiceAccountKubeConfigModel
+	var plan handlers.ServiceAccountKubeConfigModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The kubeconfig points at the server of server_kubeconfig
+	if !state.ServerKubeConfig.Equal(plan.ServerKubeConfig) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("kubeconfig"), types.StringUnknown())...)
+	}
+
+	// A new token is requested once expired, or for a new ttl
+	if !state.Expired(time.Now()) && state.Ttl.Equal(plan.Ttl) {
+		return
+	}
+	for _, attribute := range []string{"token", "expires_at", "kubeconfig"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(attribute), types.StringUnknown())...)
+	}
+}
+
+// ConfigValidators implements resource.ResourceWithConfigValidators.
+func (k *K3sServiceAccountKubeConfigResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		&k3sServiceAccountKubeConfigValidator{},
+	}
+}
+
+type k3sServiceAccountKubeConfigValidator struct{}
+
+var _ resource.ConfigValidator = &k3sServiceAccountKubeConfigValidator{}
+
+// Description implements resource.ConfigValidator.
+func (k *k3sServiceAccountKubeConfigValidator) Description(context.Context) string {
+	return "Validates the role and ttl of the service account"
+}
+
+// MarkdownDescription implements resource.ConfigValidator.
+func (k *k3sServiceAccountKubeConfigValidator) MarkdownDescription(context.Context) string {
+	return "Allows either a cluster_role or a role, and a ttl of at least 10 minutes"
+}
+
+// ValidateResource implements resource.ConfigValidator.
+func (k *k3sServiceAccountKubeConfigValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data handlers.ServiceAccountKubeConfigModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.ClusterRole.IsUnknown() || data.Role.IsUnknown() {
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		resp.Diagnostics.AddError("Service account error", err.Error())
+		return
+	}
+}
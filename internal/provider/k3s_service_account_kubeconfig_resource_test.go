@@ -0,0 +1,58 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestK3sServiceAccountKubeConfigValidateResource(t *testing.T) {
+	t.Parallel()
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:           true,
+			ExpectNonEmptyPlan: true,
+			Config: providerConfig + `
+			resource "k3s_service_account_kubeconfig" "main" {
+				server_kubeconfig = "abc123"
+				name              = "ci"
+				namespace         = "apps"
+				cluster_role      = "edit"
+				ttl               = "24h"
+			}`,
+		}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)exactly one of cluster_role or role(.*)`),
+			Config: providerConfig + `
+			resource "k3s_service_account_kubeconfig" "main" {
+				server_kubeconfig = "abc123"
+				name              = "ci"
+				cluster_role      = "edit"
+				role              = "deployer"
+			}`,
+		}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)ttl must be at least(.*)`),
+			Config: providerConfig + `
+			resource "k3s_service_account_kubeconfig" "main" {
+				server_kubeconfig = "abc123"
+				name              = "ci"
+				cluster_role      = "view"
+				ttl               = "1m"
+			}`,
+		}},
+	})
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+// Prior schemas are frozen copies, only their types matter for decoding
+// old states. Never change them, add a new version with an upgrader instead.
+
+var _ resource.ResourceWithUpgradeState = &K3sServerResource{}
+var _ resource.ResourceWithUpgradeState = &K3sAgentResource{}
+
+// UpgradeState implements resource.ResourceWithUpgradeState.
+func (s *K3sServerResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: serverSchemaV0(),
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior handlers.ServerClientModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				upgraded := prior.Upgrade(ctx)
+				resp.Diagnostics.Append(resp.State.Set(ctx, &upgraded)...)
+			},
+		},
+	}
+}
+
+// UpgradeState implements resource.ResourceWithUpgradeState.
+func (k *K3sAgentResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: agentSchemaV0(),
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior handlers.AgentClientModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				upgraded := prior.Upgrade(ctx)
+				resp.Diagnostics.Append(resp.State.Set(ctx, &upgraded)...)
+			},
+		},
+	}
+}
+
+func nodeAuthSchemaV0() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Required: true,
+		Attributes: map[string]schema.Attribute{
+			"host":        schema.StringAttribute{Optional: true},
+			"port":        schema.Int32Attribute{Optional: true, Computed: true},
+			"private_key": schema.StringAttribute{Optional: true, Sensitive: true},
+			"password":    schema.StringAttribute{Optional: true, Sensitive: true},
+			"user":        schema.StringAttribute{Optional: true},
+		},
+	}
+}
+
+func serverSchemaV0() *schema.Schema {
+	return &schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"auth":       nodeAuthSchemaV0(),
+			"bin_dir":    schema.StringAttribute{Optional: true, Computed: true},
+			"config":     schema.StringAttribute{Optional: true},
+			"registry":   schema.StringAttribute{Optional: true},
+			"id":         schema.StringAttribute{Computed: true},
+			"kubeconfig": schema.StringAttribute{Computed: true, Sensitive: true},
+			"token":      schema.StringAttribute{Computed: true, Sensitive: true},
+			"server":     schema.StringAttribute{Computed: true},
+			"active":     schema.BoolAttribute{Computed: true},
+			"highly_available": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cluster_init": schema.BoolAttribute{Optional: true, Computed: true},
+					"token":        schema.StringAttribute{Optional: true, Sensitive: true},
+					"server":       schema.StringAttribute{Optional: true},
+				},
+			},
+			"oidc": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"audience":    schema.StringAttribute{Required: true},
+					"pkcs8":       schema.StringAttribute{Required: true, Sensitive: true},
+					"signing_key": schema.StringAttribute{Required: true, Sensitive: true},
+					"issuer":      schema.StringAttribute{Required: true},
+					"jwks_keys":   schema.StringAttribute{Computed: true},
+				},
+			},
+			"cluster_auth": schema.SingleNestedAttribute{
+				Computed: true,
+				Attributes: map[string]schema.Attribute{
+					"client_certificate_data":    schema.StringAttribute{Computed: true, Sensitive: true},
+					"certificate_authority_data": schema.StringAttribute{Computed: true, Sensitive: true},
+					"client_key_data":            schema.StringAttribute{Computed: true, Sensitive: true},
+					"server":                     schema.StringAttribute{Computed: true},
+				},
+			},
+		},
+	}
+}
+
+func agentSchemaV0() *schema.Schema {
+	return &schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"auth":             nodeAuthSchemaV0(),
+			"bin_dir":          schema.StringAttribute{Optional: true, Computed: true},
+			"config":           schema.StringAttribute{Optional: true},
+			"registry":         schema.StringAttribute{Optional: true},
+			"allow_delete_err": schema.BoolAttribute{Optional: true},
+			"token":            schema.StringAttribute{Required: true, Sensitive: true},
+			"server":           schema.StringAttribute{Required: true},
+			"kubeconfig":       schema.StringAttribute{Required: true},
+			"id":               schema.StringAttribute{Computed: true},
+			"active":           schema.BoolAttribute{Computed: true},
+		},
+	}
+}
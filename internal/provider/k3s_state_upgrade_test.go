@@ -0,0 +1,142 @@
+package provider_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	provider "striveworks.us/terraform-provider-k3s/internal/provider"
+)
+
+// Upgrades a recorded state through the provider server, like terraform would
+// after a provider upgrade, and returns the attributes of the new state.
+func upgradeState(t *testing.T, typeName string, version int64, fixture string) map[string]tftypes.Value {
+	raw, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := providerserver.NewProtocol6WithError(provider.New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := server.GetProviderSchema(t.Context(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, ok := schemas.ResourceSchemas[typeName]
+	if !ok {
+		t.Fatalf("no schema for %s", typeName)
+	}
+
+	resp, err := server.UpgradeResourceState(t.Context(), &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  version,
+		RawState: &tfprotov6.RawState{JSON: raw},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	state, err := resp.UpgradedState.Unmarshal(schema.ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	if err := state.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	return attrs
+}
+
+func stringAttr(t *testing.T, v tftypes.Value) string {
+	var s string
+	if err := v.As(&s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestUpgradeServerStateV0(t *testing.T) {
+	attrs := upgradeState(t, "k3s_server", 0, "testdata/k3s_server_v0.json")
+
+	if got := stringAttr(t, attrs["id"]); got != "10.0.0.10" {
+		t.Errorf("id: got %q", got)
+	}
+	if got := stringAttr(t, attrs["token"]); got != "K10::server:secret" {
+		t.Errorf("token: got %q", got)
+	}
+	if got := stringAttr(t, attrs["config"]); got != "write-kubeconfig-mode: \"0644\"\ntls-san:\n  - k3s.example.com\n" {
+		t.Errorf("config: got %q", got)
+	}
+	if !attrs["agent_token"].IsNull() {
+		t.Error("agent_token should be null until the next read")
+	}
+	if !attrs["wait_for_ready"].IsNull() {
+		t.Error("wait_for_ready should be null")
+	}
+
+	var ha map[string]tftypes.Value
+	if err := attrs["highly_available"].As(&ha); err != nil {
+		t.Fatal(err)
+	}
+	var clusterInit bool
+	if err := ha["cluster_init"].As(&clusterInit); err != nil || !clusterInit {
+		t.Errorf("highly_available.cluster_init: got %v", clusterInit)
+	}
+	if !ha["agent_token"].IsNull() {
+		t.Error("highly_available.agent_token should be null")
+	}
+
+	var auth map[string]tftypes.Value
+	if err := attrs["auth"].As(&auth); err != nil {
+		t.Fatal(err)
+	}
+	if got := stringAttr(t, auth["user"]); got != "ubuntu" {
+		t.Errorf("auth.user: got %q", got)
+	}
+
+	var clusterAuth map[string]tftypes.Value
+	if err := attrs["cluster_auth"].As(&clusterAuth); err != nil {
+		t.Fatal(err)
+	}
+	if got := stringAttr(t, clusterAuth["server"]); got != "https://10.0.0.10:6443" {
+		t.Errorf("cluster_auth.server: got %q", got)
+	}
+}
+
+func TestUpgradeAgentStateV0(t *testing.T) {
+	attrs := upgradeState(t, "k3s_agent", 0, "testdata/k3s_agent_v0.json")
+
+	if got := stringAttr(t, attrs["id"]); got != "10.0.0.20" {
+		t.Errorf("id: got %q", got)
+	}
+	if got := stringAttr(t, attrs["token"]); got != "K10::server:secret" {
+		t.Errorf("token: got %q", got)
+	}
+	if got := stringAttr(t, attrs["config"]); got != "node-label:\n  - role=worker\n" {
+		t.Errorf("config: got %q", got)
+	}
+	if !attrs["agent_token"].IsNull() {
+		t.Error("agent_token should be null")
+	}
+	if !attrs["registry"].IsNull() {
+		t.Error("registry should stay null")
+	}
+
+	var auth map[string]tftypes.Value
+	if err := attrs["auth"].As(&auth); err != nil {
+		t.Fatal(err)
+	}
+	if got := stringAttr(t, auth["password"]); got != "hunter2" {
+		t.Errorf("auth.password: got %q", got)
+	}
+}
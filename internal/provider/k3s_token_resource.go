@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+	"striveworks.us/terraform-provider-k3s/internal/k3s"
+)
+
+var _ resource.ResourceWithConfigValidators = &K3sTokenResource{}
+var _ resource.ResourceWithModifyPlan = &K3sTokenResource{}
+
+var _ resource.ResourceWithConfigure = &K3sTokenResource{}
+
+type K3sTokenResource struct {
+	defaults *handlers.DefaultAuth
+}
+
+func NewK3sTokenResource() resource.Resource {
+	return &K3sTokenResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (k *K3sTokenResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*K3sProvider)
+	if !ok {
+		resp.Diagnostics.AddError("Provider error", "Could not convert provider data")
+		return
+	}
+	k.defaults = provider.DefaultAuth
+}
+
+// Metadata implements resource.Resource.
+func (k *K3sTokenResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_token"
+}
+
+// Schema implements resource.Resource.
+func (k *K3sTokenResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: ("Manages join tokens of a k3s cluster through one of its servers. By default creates a time limited " +
+			"bootstrap token with `k3s token create`, deleted again on destroy. When `rotate` is passed, the server token of the " +
+			"cluster is rotated with `k3s token rotate` instead and every listed server is restarted with the new token. " +
+			"A rotation cannot be undone, destroying it only removes it from state."),
+		Attributes: map[string]schema.Attribute{
+			"auth": handlers.NodeAuth{}.Schema(),
+			"ttl": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Lifetime of the bootstrap token, e.g. `24h`. `0` never expires. Defaults to k3s' own default of 24 hours",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"description": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Human readable description of the bootstrap token",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rotate": handlers.TokenRotation{}.Schema(),
+			// Outputs
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the bootstrap token, or of the rotation",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"token": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Token usable to join nodes to the cluster",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (k *K3sTokenResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data handlers.TokenClientModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
+	data.Auth = auth.StateObject(ctx)
+	server := k3s.NewK3ServerUninstall(ctx, "")
+
+	if err := data.Create(ctx, &auth, server); err != nil {
+		resp.Diagnostics.AddError("creating k3s token", err.Error())
+		return
+	}
+
+	tflog.Info(ctx, "Created a k3s token resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read implements resource.Resource.
+func (k *K3sTokenResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data handlers.TokenClientModel
+
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
+	server := k3s.NewK3ServerUninstall(ctx, "")
+
+	exists, err := data.Read(ctx, &auth, server)
+	if err != nil {
+		resp.Diagnostics.AddError("reading k3s token", err.Error())
+		return
+	}
+
+	if !exists {
+		tflog.Warn(ctx, "k3s bootstrap token no longer exists, removing from state")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update implements resource.Resource.
+func (k *K3sTokenResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data handlers.TokenClientModel
+	var state handlers.TokenClientModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
+	server := k3s.NewK3ServerUninstall(ctx, "")
+
+	if err := state.Update(ctx, data, &auth, server); err != nil {
+		resp.Diagnostics.AddError("updating k3s token", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete implements resource.Resource.
+func (k *K3sTokenResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data handlers.TokenClientModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auth := handlers.NewNodeAuth(ctx, data.Auth).WithDefaults(k.defaults)
+	server := k3s.NewK3ServerUninstall(ctx, "")
+
+	if err := data.Delete(ctx, &auth, server); err != nil {
+		resp.Diagnostics.AddError("deleting k3s token", err.Error())
+		return
+	}
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.
+func (k *K3sTokenResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Destroying
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var rotate types.Object
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("rotate"), &rotate)...)
+	if resp.Diagnostics.HasError() || rotate.IsNull() {
+		return
+	}
+
+	// A rotation's token is always the token rotated to
+	rotation := handlers.NewTokenRotation(ctx, rotate)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("token"), rotation.NewToken)...)
+}
+
+// ConfigValidators implements resource.ResourceWithConfigValidators.
+func (k *K3sTokenResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		&k3sTokenValidator{},
+	}
+}
+
+type k3sTokenValidator struct{}
+
+var _ resource.ConfigValidator = &k3sTokenValidator{}
+
+// Description implements resource.ConfigValidator.
+func (k *k3sTokenValidator) Description(context.Context) string {
+	return "Validates the authentication and token mode"
+}
+
+// MarkdownDescription implements resource.ConfigValidator.
+func (k *k3sTokenValidator) MarkdownDescription(context.Context) string {
+	return "Allows either Password or Private Key, and either a bootstrap token or a rotation"
+}
+
+// ValidateResource implements resource.ConfigValidator.
+func (k *k3sTokenValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data handlers.TokenClientModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if err := handlers.NewNodeAuth(ctx, data.Auth).Validate(); err != nil {
+		resp.Diagnostics.AddError("No auth", err.Error())
+		return
+	}
+
+	if !data.Rotate.IsNull() && (!data.Ttl.IsNull() || !data.Description.IsNull()) {
+		resp.Diagnostics.AddError("Token error", "ttl and description only apply to bootstrap tokens, not to a rotation")
+		return
+	}
+
+	if !data.Ttl.IsNull() && !data.Ttl.IsUnknown() {
+		if _, err := time.ParseDuration(data.Ttl.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ttl"), "Token error", err.Error())
+			return
+		}
+	}
+}
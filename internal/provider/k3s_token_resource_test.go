@@ -0,0 +1,68 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestK3sTokenValidateResource(t *testing.T) {
+	t.Parallel()
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:           true,
+			ExpectNonEmptyPlan: true,
+			Config: providerConfig + `
+			resource "k3s_token" "main" {
+				auth = {
+				host	    = "192.168.1.2"
+				user	    = "ubuntu"
+				private_key = "abc123"
+				}
+				ttl         = "24h"
+				description = "abc123"
+			}`,
+		}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)invalid duration(.*)`),
+			Config: providerConfig + `
+			resource "k3s_token" "main" {
+				auth = {
+				host	    = "192.168.1.2"
+				user	    = "ubuntu"
+				private_key = "abc123"
+				}
+				ttl = "a day"
+			}`,
+		}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		IsUnitTest:               true,
+		Steps: []resource.TestStep{{
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(.*)only apply to bootstrap tokens(.*)`),
+			Config: providerConfig + `
+			resource "k3s_token" "main" {
+				auth = {
+				host	    = "192.168.1.2"
+				user	    = "ubuntu"
+				private_key = "abc123"
+				}
+				ttl    = "24h"
+				rotate = {
+				servers = ["192.168.1.3"]
+				}
+			}`,
+		}},
+	})
+
+}
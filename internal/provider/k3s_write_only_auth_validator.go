@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
+)
+
+// Write-only credentials are only there on create and update, so reading
+// and destroying a node that takes them from `auth_wo` relies on the
+// provider's default_auth for credentials.
+type k3sWriteOnlyAuthValidator struct {
+	defaults *handlers.DefaultAuth
+}
+
+var _ resource.ConfigValidator = &k3sWriteOnlyAuthValidator{}
+
+// Description implements resource.ConfigValidator.
+func (k *k3sWriteOnlyAuthValidator) Description(context.Context) string {
+	return "Validates the provider can reach the node without its write-only credentials"
+}
+
+// MarkdownDescription implements resource.ConfigValidator.
+func (k *k3sWriteOnlyAuthValidator) MarkdownDescription(context.Context) string {
+	return "Requires credentials in the provider's `default_auth` when `auth_wo` is passed"
+}
+
+// ValidateResource implements resource.ConfigValidator.
+func (k *k3sWriteOnlyAuthValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var authWo types.Object
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("auth_wo"), &authWo)...)
+	// The provider isn't configured when validating on its own
+	if resp.Diagnostics.HasError() || authWo.IsNull() || k.defaults == nil {
+		return
+	}
+
+	if !k.defaults.HasCredentials() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("auth_wo"),
+			"Missing default auth",
+			"auth_wo credentials aren't kept in state, set a private key or password in the provider's default_auth, "+
+				"or K3S_SSH_PRIVATE_KEY or K3S_SSH_PASSWORD, so the node can be read and destroyed",
+		)
+	}
+}
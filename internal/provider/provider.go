@@ -6,15 +6,20 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"striveworks.us/terraform-provider-k3s/internal/handlers"
 )
 
 var (
-	_ provider.Provider = &K3sProvider{}
+	_ provider.Provider                       = &K3sProvider{}
+	_ provider.ProviderWithEphemeralResources = &K3sProvider{}
+	_ provider.ProviderWithFunctions          = &K3sProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -38,12 +43,16 @@ func NewDebugMode(version string) func() provider.Provider {
 type K3sProvider struct {
 	Version   string
 	DebugMode bool
+	// Ssh settings nodes fall back to
+	DefaultAuth *handlers.DefaultAuth
 }
 
 type k3sProviderModel struct {
 	// K3s version to select, if not selected
 	// will default to latest
 	Version types.String `tfsdk:"k3s_version"`
+	// Ssh settings shared by all nodes
+	DefaultAuth types.Object `tfsdk:"default_auth"`
 }
 
 // Metadata returns the provider type name.
@@ -62,6 +71,7 @@ func (p *K3sProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *
 				Optional:    true,
 				Description: "K3s version to select, if not selected will default to latest",
 			},
+			"default_auth": handlers.DefaultAuth{}.Schema(),
 		},
 	}
 }
@@ -101,18 +111,43 @@ func (p *K3sProvider) Configure(ctx context.Context, req provider.ConfigureReque
 
 	p.Version = version
 
+	defaults, err := handlers.NewDefaultAuth(ctx, config.DefaultAuth)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("default_auth"), "Invalid default auth", err.Error())
+	}
+	p.DefaultAuth = defaults
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	resp.ResourceData = p
 	resp.DataSourceData = p
+	resp.EphemeralResourceData = p
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *K3sProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewK3sKubeConfigData,
+		NewK3sEtcdSnapshotsData,
+		NewK3sMergedKubeConfigData,
+	}
+}
+
+// EphemeralResources defines the ephemeral resources implemented in the provider.
+func (p *K3sProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewK3sKubeConfigEphemeral,
+	}
+}
+
+// Functions defines the provider functions implemented in the provider.
+func (p *K3sProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewKubeConfigSetServerFunction,
+		NewKubeConfigExtractAuthFunction,
+		NewConfigMergeFunction,
 	}
 }
 
@@ -121,5 +156,10 @@ func (p *K3sProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewK3sServerResource,
 		NewK3sAgentResource,
+		NewK3sTokenResource,
+		NewK3sCertificateRotationResource,
+		NewK3sServiceAccountKubeConfigResource,
+		NewK3sClientCertificateKubeConfigResource,
+		NewK3sClusterResource,
 	}
 }
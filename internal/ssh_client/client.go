@@ -6,20 +6,45 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"regexp"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"golang.org/x/crypto/ssh"
 )
 
-func NewSSHClient(ctx context.Context, hostnameOrIpAddress string, port int, user string, pem string, password string) (SSHClient, error) {
+func NewSSHClient(ctx context.Context, hostnameOrIpAddress string, port int, user string, pem string, password string, opts ...Option) (SSHClient, error) {
+	config, err := clientConfig(ctx, user, pem, password)
+	if err != nil {
+		return nil, err
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("Using auth against %s", hostnameOrIpAddress))
+	client := &sshClient{
+		ctx:                 ctx,
+		hostnameOrIpAddress: hostnameOrIpAddress,
+		port:                port,
+		config:              config,
+	}
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func clientConfig(ctx context.Context, user string, pem string, password string) (ssh.ClientConfig, error) {
 	var auth ssh.AuthMethod
 	if pem != "" {
 		tflog.MaskMessageStrings(ctx, pem)
 		signer, err := signerFromPem([]byte(pem))
 		if err != nil {
-			return nil, err
+			return ssh.ClientConfig{}, err
 		}
 		auth = ssh.PublicKeys(signer)
 	} else {
@@ -28,20 +53,14 @@ func NewSSHClient(ctx context.Context, hostnameOrIpAddress string, port int, use
 		auth = ssh.Password(password)
 	}
 
-	tflog.Info(ctx, fmt.Sprintf("Using auth against %s", hostnameOrIpAddress))
-	return &sshClient{
-		ctx:                 ctx,
-		hostnameOrIpAddress: hostnameOrIpAddress,
-		port:                port,
-		config: ssh.ClientConfig{
-			User: user,
-			Auth: []ssh.AuthMethod{
-				auth,
-			},
-			// In production, implement proper host key verification
-			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-			// Timeout:         60,
-		}}, nil
+	return ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{
+			auth,
+		},
+		// In production, implement proper host key verification
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}, nil
 }
 
 type SSHRun interface {
@@ -94,6 +113,10 @@ type sshClient struct {
 	hostnameOrIpAddress string
 	port                int
 	ctx                 context.Context
+	// Optional settings
+	bastion        *bastion
+	connectTimeout time.Duration
+	readyTimeout   time.Duration
 }
 
 func (s *sshClient) HostnameOrIpAddress() string {
@@ -129,8 +152,73 @@ func (s *sshClient) Run(commands ...string) (results []string, err error) {
 	return
 }
 
+// Dials the host, through the bastion if any. Connecting is bounded by the
+// connect timeout, the connection and every session on it by the deadline of
+// the client context.
+func (s *sshClient) dial() (*ssh.Client, error) {
+	ctx := s.ctx
+	if s.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.connectTimeout)
+		defer cancel()
+	}
+
+	dialer := net.Dialer{}
+	if s.bastion == nil {
+		conn, err := dialer.DialContext(ctx, "tcp", s.Host())
+		if err != nil {
+			return nil, err
+		}
+		return s.handshake(ctx, conn, s.Host(), &s.config)
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", s.bastion.host)
+	if err != nil {
+		return nil, fmt.Errorf("dialing bastion %s: %v", s.bastion.host, err)
+	}
+	bastion, err := s.handshake(ctx, conn, s.bastion.host, &s.bastion.config)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to bastion %s: %v", s.bastion.host, err)
+	}
+
+	conn, err = bastion.DialContext(ctx, "tcp", s.Host())
+	if err != nil {
+		bastion.Close()
+		return nil, fmt.Errorf("dialing %s through bastion: %v", s.Host(), err)
+	}
+	client, err := s.handshake(ctx, conn, s.Host(), &s.config)
+	if err != nil {
+		bastion.Close()
+		return nil, err
+	}
+
+	// The bastion connection lives as long as the one tunneled through it
+	go func() {
+		_ = client.Wait()
+		bastion.Close()
+	}()
+	return client, nil
+}
+
+// Runs the ssh handshake over conn. Connections tunneled through a bastion
+// don't support deadlines, those are bounded by the bastion connection instead.
+func (s *sshClient) handshake(ctx context.Context, conn net.Conn, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	deadline, _ = s.ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
 func (s *sshClient) runSingle(command string) (result string, err error) {
-	client, err := ssh.Dial("tcp", s.Host(), &s.config)
+	client, err := s.dial()
 	if err != nil {
 		return result, fmt.Errorf("create client failed %v", err)
 	}
@@ -163,7 +251,7 @@ func (s *sshClient) RunStream(commands []string) (err error) {
 }
 
 func (s *sshClient) streamSingle(command string) error {
-	client, err := ssh.Dial("tcp", s.Host(), &s.config)
+	client, err := s.dial()
 	if err != nil {
 		return fmt.Errorf("create client failed %v", err)
 	}
@@ -225,24 +313,60 @@ func (s *sshClient) streamSingle(command string) error {
 	return nil
 }
 
+// WaitForReady implements SSHClient. Polls until the ready timeout or the
+// deadline of the client context, or for a fixed number of attempts when
+// there is neither. Rejected credentials or host keys fail at once, waiting
+// won't fix them.
 func (s *sshClient) WaitForReady() error {
+	ctx := s.ctx
+	if s.readyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.readyTimeout)
+		defer cancel()
+	}
+
+	// Without a deadline the attempts are capped instead
 	maxRetries := 10
-	for i := range maxRetries {
-		client, err := ssh.Dial("tcp", s.Host(), &s.config)
+	_, deadline := ctx.Deadline()
+	for i := 0; ; i++ {
+		client, err := s.dial()
 		if err == nil {
 			client.Close()
-			break
-		} else {
-			tflog.Warn(s.ctx, fmt.Sprintf("While waiting for ssh to be ready %s", err.Error()))
+			return nil
+		}
+		if permanent(err) {
+			return fmt.Errorf("SSH connection refused: %v", err)
 		}
-		if i == maxRetries-1 {
+		tflog.Warn(s.ctx, fmt.Sprintf("While waiting for ssh to be ready %s", err.Error()))
+
+		if !deadline && i == maxRetries-1 {
 			return fmt.Errorf("SSH not ready after %d attempts: %v", maxRetries, err)
 		}
-		tflog.Info(s.ctx, fmt.Sprintf("Waiting for SSH to be ready... (%d/%d)", i+1, maxRetries))
-		time.Sleep(5 * time.Second)
+		tflog.Info(s.ctx, fmt.Sprintf("Waiting for SSH to be ready... (%d)", i+1))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("SSH not ready before timeout: %v", err)
+		case <-time.After(5 * time.Second):
+		}
 	}
+}
 
-	return nil
+// Whether a dial failed on the ssh handshake itself, such as rejected
+// credentials or host key, rather than the host still starting up. A
+// handshake cut short by a closed connection or a timeout is still transient.
+func permanent(err error) bool {
+	if strings.Contains(err.Error(), "ssh: unable to authenticate") {
+		return true
+	}
+	if !strings.Contains(err.Error(), "ssh: handshake failed") {
+		return false
+	}
+	var netErr net.Error
+	if errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return false
+	}
+	return true
 }
 
 func (s *sshClient) ReadFile(path string, missingOk bool, sudo bool) (string, error) {
@@ -0,0 +1,44 @@
+package ssh_client
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Optional settings of an SSHClient.
+type Option func(*sshClient) error
+
+type bastion struct {
+	host   string
+	config ssh.ClientConfig
+}
+
+// Connects to the host through a bastion (jump) host.
+func WithBastion(hostnameOrIpAddress string, port int, user string, pem string, password string) Option {
+	return func(s *sshClient) error {
+		config, err := clientConfig(s.ctx, user, pem, password)
+		if err != nil {
+			return fmt.Errorf("bastion auth: %s", err.Error())
+		}
+		s.bastion = &bastion{host: fmt.Sprintf("%s:%d", hostnameOrIpAddress, port), config: config}
+		return nil
+	}
+}
+
+// Bounds establishing each ssh connection.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(s *sshClient) error {
+		s.connectTimeout = timeout
+		return nil
+	}
+}
+
+// Bounds how long WaitForReady polls for the host to accept connections.
+func WithReadyTimeout(timeout time.Duration) Option {
+	return func(s *sshClient) error {
+		s.readyTimeout = timeout
+		return nil
+	}
+}